@@ -0,0 +1,124 @@
+package balancer
+
+import (
+	"context"
+	"sync"
+
+	"github.com/leon-gopher/discovery/errors"
+	"github.com/leon-gopher/discovery/registry"
+)
+
+// Balancer picks service instances with pickers which are rebuilt automatically from Resolver updates.
+type Balancer struct {
+	mux      sync.RWMutex
+	registry Resolver
+	builder  Builder
+	pickers  map[registry.ServiceKey]Picker
+	cancels  map[registry.ServiceKey]func()
+	closed   bool
+}
+
+// New creates a *Balancer with the registry and the builder given.
+func New(r Resolver, builder Builder) *Balancer {
+	return &Balancer{
+		registry: r,
+		builder:  builder,
+		pickers:  make(map[registry.ServiceKey]Picker),
+		cancels:  make(map[registry.ServiceKey]func()),
+	}
+}
+
+// NewWithName creates a *Balancer with the builder registered by name.
+func NewWithName(r Resolver, name string) (*Balancer, error) {
+	builder := Get(name)
+	if builder == nil {
+		return nil, errors.Wrap(errors.ErrBalancerNotImplemented)
+	}
+
+	return New(r, builder), nil
+}
+
+// Builder returns the Builder of balancer.
+func (b *Balancer) Builder() Builder {
+	return b.builder
+}
+
+// Pick picks a service instance of the name, it resolves services from registry for the first time.
+func (b *Balancer) Pick(name string, info PickInfo, opts ...registry.DiscoveryOption) (*registry.Service, DoneFunc, error) {
+	return b.PickContext(context.Background(), name, info, opts...)
+}
+
+// PickContext is Pick bounded by ctx, which bounds resolving services from registry for the first time.
+func (b *Balancer) PickContext(ctx context.Context, name string, info PickInfo, opts ...registry.DiscoveryOption) (*registry.Service, DoneFunc, error) {
+	picker, err := b.picker(ctx, name, opts...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return picker.Pick(info)
+}
+
+func (b *Balancer) picker(ctx context.Context, name string, opts ...registry.DiscoveryOption) (Picker, error) {
+	o := registry.NewCommonDiscoveryOption(opts...)
+	key := registry.NewServiceKey(name, o.Tags, o.DC)
+
+	b.mux.RLock()
+	picker, ok := b.pickers[key]
+	b.mux.RUnlock()
+	if ok {
+		return picker, nil
+	}
+
+	// watch before lookup, so no update missed in between
+	b.mux.Lock()
+	if _, ok := b.cancels[key]; !ok && !b.closed {
+		b.cancels[key] = b.registry.WithWatcherFunc(key, registry.WatchFunc(b.rebuild))
+	}
+	b.mux.Unlock()
+
+	services, err := b.registry.LookupServicesContext(ctx, name, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	b.mux.Lock()
+	defer b.mux.Unlock()
+
+	// built by watch updates arrived during lookup, or concurrent first picks
+	if picker, ok := b.pickers[key]; ok {
+		return picker, nil
+	}
+
+	picker = BuildKey(b.builder, key, services)
+	if _, ok := b.cancels[key]; ok {
+		b.pickers[key] = picker
+	}
+
+	return picker, nil
+}
+
+func (b *Balancer) rebuild(key registry.ServiceKey, services []*registry.Service) {
+	picker := BuildKey(b.builder, key, services)
+
+	b.mux.Lock()
+	if _, ok := b.cancels[key]; ok {
+		b.pickers[key] = picker
+	}
+	b.mux.Unlock()
+}
+
+// Close stops watching services picked from registry and releases their pickers, picks after closing resolve services
+// from registry every time.
+func (b *Balancer) Close() {
+	b.mux.Lock()
+	cancels := b.cancels
+
+	b.closed = true
+	b.pickers = make(map[registry.ServiceKey]Picker)
+	b.cancels = make(map[registry.ServiceKey]func())
+	b.mux.Unlock()
+
+	for _, cancel := range cancels {
+		cancel()
+	}
+}
@@ -0,0 +1,130 @@
+package balancer
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/leon-gopher/discovery/errors"
+	"github.com/leon-gopher/discovery/registry"
+)
+
+// fakeResolver returns services set, and calls during before returning lookups if it's set.
+type fakeResolver struct {
+	mux      sync.Mutex
+	services []*registry.Service
+	err      error
+	lookups  int
+	watchers map[registry.ServiceKey]registry.Watcher
+	during   func()
+}
+
+func (r *fakeResolver) LookupServicesContext(ctx context.Context, name string, opts ...registry.DiscoveryOption) ([]*registry.Service, error) {
+	r.mux.Lock()
+	r.lookups++
+	services, err, during := r.services, r.err, r.during
+	r.mux.Unlock()
+
+	if during != nil {
+		during()
+	}
+
+	return services, err
+}
+
+func (r *fakeResolver) WithWatcherFunc(key registry.ServiceKey, w registry.Watcher) (cancel func()) {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+
+	if r.watchers == nil {
+		r.watchers = make(map[registry.ServiceKey]registry.Watcher)
+	}
+	r.watchers[key] = w
+
+	return func() {
+		r.mux.Lock()
+		delete(r.watchers, key)
+		r.mux.Unlock()
+	}
+}
+
+func (r *fakeResolver) push(key registry.ServiceKey, services []*registry.Service) {
+	r.mux.Lock()
+	w := r.watchers[key]
+	r.mux.Unlock()
+
+	if w != nil {
+		w.Watch(key, services)
+	}
+}
+
+func pickID(t *testing.T, b *Balancer) string {
+	t.Helper()
+
+	service, done, err := b.Pick("svc", PickInfo{})
+	if err != nil {
+		t.Fatalf("Pick(): %v", err)
+	}
+	done(nil)
+
+	return service.ID
+}
+
+func TestBalancerWatchesBeforeLookup(t *testing.T) {
+	key := registry.NewServiceKey("svc", nil, "")
+
+	r := &fakeResolver{services: []*registry.Service{{ID: "stale"}}}
+	r.during = func() {
+		// an update arrives while the first lookup is in flight
+		r.push(key, []*registry.Service{{ID: "fresh"}})
+	}
+
+	b := New(r, BuilderFunc(NewRoundRobin))
+	if id := pickID(t, b); id != "fresh" {
+		t.Fatalf("picked %s, expected fresh", id)
+	}
+
+	r.mux.Lock()
+	r.during = nil
+	r.mux.Unlock()
+
+	r.push(key, []*registry.Service{{ID: "next"}})
+	if id := pickID(t, b); id != "next" {
+		t.Fatalf("picked %s, expected next", id)
+	}
+	if r.lookups != 1 {
+		t.Fatalf("lookups: %d, expected 1", r.lookups)
+	}
+
+	b.Close()
+	if len(r.watchers) != 0 {
+		t.Fatalf("watchers not canceled: %v", r.watchers)
+	}
+
+	// picks after closing resolve every time
+	pickID(t, b)
+	pickID(t, b)
+	if r.lookups != 3 || len(r.watchers) != 0 {
+		t.Fatalf("lookups: %d, watchers: %d, expected 3, 0", r.lookups, len(r.watchers))
+	}
+}
+
+func TestBalancerLookupFailed(t *testing.T) {
+	key := registry.NewServiceKey("svc", nil, "")
+
+	r := &fakeResolver{err: errors.ErrNotFound}
+
+	b := New(r, BuilderFunc(NewRoundRobin))
+	if _, _, err := b.Pick("svc", PickInfo{}); !errors.Is(err, errors.ErrNotFound) {
+		t.Fatalf("Pick(): %v, expected %v", err, errors.ErrNotFound)
+	}
+
+	// services watched after a failed lookup are picked
+	r.push(key, []*registry.Service{{ID: "a"}})
+	if id := pickID(t, b); id != "a" {
+		t.Fatalf("picked %s, expected a", id)
+	}
+	if r.lookups != 1 {
+		t.Fatalf("lookups: %d, expected 1", r.lookups)
+	}
+}
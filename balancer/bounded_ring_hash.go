@@ -0,0 +1,67 @@
+package balancer
+
+import (
+	"math"
+	"sync"
+
+	"github.com/leon-gopher/discovery/errors"
+	"github.com/leon-gopher/discovery/registry"
+)
+
+// boundedRingHash implements consistent hashing with bounded loads, a key walks clockwise the ring to
+// the next service when its owner has more than factor * average inflight requests.
+type boundedRingHash struct {
+	*ringHash
+
+	mux    sync.Mutex
+	factor float64
+	loads  []int64
+	total  int64
+}
+
+// NewBoundedRingHashBuilder creates a Builder of consistent hashing with bounded loads, factor should be greater than 1.
+func NewBoundedRingHashBuilder(factor float64) Builder {
+	if factor < 1 {
+		factor = DefaultBoundedLoadFactor
+	}
+
+	return BuilderFunc(func(services []*registry.Service) Picker {
+		return &boundedRingHash{
+			ringHash: newRingHash(services),
+			factor:   factor,
+			loads:    make([]int64, len(services)),
+		}
+	})
+}
+
+func (p *boundedRingHash) Pick(info PickInfo) (*registry.Service, DoneFunc, error) {
+	if len(p.ring) == 0 {
+		return nil, nil, errors.Wrap(errors.ErrNotFound)
+	}
+
+	p.mux.Lock()
+	defer p.mux.Unlock()
+
+	capacity := int64(math.Ceil(p.factor * float64(p.total+1) / float64(len(p.services))))
+
+	idx := p.search(hashKey(info.Key))
+	for i := 0; i < len(p.ring); i++ {
+		node := p.ring[(idx+i)%len(p.ring)]
+		if p.loads[node.index] >= capacity {
+			continue
+		}
+
+		p.loads[node.index]++
+		p.total++
+
+		return p.services[node.index], func(error) {
+			p.mux.Lock()
+			p.loads[node.index]--
+			p.total--
+			p.mux.Unlock()
+		}, nil
+	}
+
+	// never reached since capacity is greater than average load
+	return nil, nil, errors.Wrap(errors.ErrNotFound)
+}
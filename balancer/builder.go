@@ -0,0 +1,34 @@
+package balancer
+
+import "sync"
+
+var (
+	builders    = make(map[string]Builder)
+	buildersMux sync.RWMutex
+)
+
+func init() {
+	Register(RoundRobin, BuilderFunc(NewRoundRobin))
+	Register(WeightedRoundRobin, BuilderFunc(NewWeightedRoundRobin))
+	Register(WeightedRandom, BuilderFunc(NewWeightedRandom))
+	Register(P2C, BuilderFunc(NewP2C))
+	Register(RingHash, BuilderFunc(NewRingHash))
+	Register(BoundedRingHash, NewBoundedRingHashBuilder(DefaultBoundedLoadFactor))
+	Register(Maglev, BuilderFunc(NewMaglev))
+	Register(Locality, NewLocalityBuilder(BuilderFunc(NewWeightedRoundRobin)))
+}
+
+// Register registers a Builder with name, it overwrites the registered one of the same name.
+func Register(name string, builder Builder) {
+	buildersMux.Lock()
+	builders[name] = builder
+	buildersMux.Unlock()
+}
+
+// Get returns the Builder registered with name, nil if not found.
+func Get(name string) Builder {
+	buildersMux.RLock()
+	defer buildersMux.RUnlock()
+
+	return builders[name]
+}
@@ -0,0 +1,34 @@
+package balancer
+
+import "time"
+
+// built-in balancer names
+const (
+	RoundRobin         = "round_robin"
+	WeightedRoundRobin = "weighted_round_robin"
+	WeightedRandom     = "weighted_random"
+	P2C                = "p2c"
+	RingHash           = "ring_hash"
+	BoundedRingHash    = "bounded_ring_hash"
+	Maglev             = "maglev"
+	// Locality prefers services in the same zone, then the same cloud, and picks by weighted round robin.
+	Locality = "locality"
+)
+
+const (
+	// DefaultRingReplicas is count of virtual nodes for service with DefaultBaseWeight.
+	DefaultRingReplicas = 160
+	// DefaultBaseWeight is the weight of service registered by default.
+	DefaultBaseWeight = 100
+	// DefaultBoundedLoadFactor limits load of each service to factor * average load.
+	DefaultBoundedLoadFactor = 1.25
+	// DefaultMaglevTableSize must be a prime number much larger than count of services.
+	DefaultMaglevTableSize = 65537
+)
+
+const (
+	// DefaultMinHealthyPercent of local pool for locality routing.
+	DefaultMinHealthyPercent = 70
+	// DefaultLocalityCalmInterval for lowering peak of a shrunk local pool.
+	DefaultLocalityCalmInterval = 1 * time.Hour
+)
@@ -0,0 +1,246 @@
+package balancer
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/leon-gopher/discovery/registry"
+)
+
+var hashBuilders = []struct {
+	name  string
+	build func([]*registry.Service) Picker
+	// max ratio of keys moved between services unchanged
+	maxDisruption float64
+}{
+	{
+		name:  RingHash,
+		build: NewRingHash,
+	},
+	{
+		name:  BoundedRingHash,
+		build: NewBoundedRingHashBuilder(DefaultBoundedLoadFactor).Build,
+	},
+	{
+		name:          Maglev,
+		build:         NewMaglev,
+		maxDisruption: 0.02,
+	},
+}
+
+func newHashServices(weights ...int32) []*registry.Service {
+	services := make([]*registry.Service, 0, len(weights))
+	for i, weight := range weights {
+		services = append(services, &registry.Service{
+			ID:     fmt.Sprintf("svc-%d", i),
+			Name:   "svc",
+			IP:     "127.0.0.1",
+			Port:   8000 + i,
+			Weight: weight,
+		})
+	}
+
+	return services
+}
+
+// owners picks every key once and returns id of the service owned it.
+func owners(t *testing.T, picker Picker, keys int) map[string]string {
+	t.Helper()
+
+	result := make(map[string]string, keys)
+	for i := 0; i < keys; i++ {
+		key := fmt.Sprintf("key-%d", i)
+
+		service, done, err := picker.Pick(PickInfo{Key: key})
+		if err != nil {
+			t.Fatalf("Pick(%s): %v", key, err)
+		}
+		done(nil)
+
+		result[key] = service.ID
+	}
+
+	return result
+}
+
+func TestHashPickersEmpty(t *testing.T) {
+	for _, tc := range hashBuilders {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, _, err := tc.build(nil).Pick(PickInfo{Key: "key"}); err == nil {
+				t.Fatal("Pick() of empty services should fail")
+			}
+		})
+	}
+}
+
+func TestHashPickersStable(t *testing.T) {
+	services := newHashServices(100, 100, 100, 100, 100)
+
+	for _, tc := range hashBuilders {
+		t.Run(tc.name, func(t *testing.T) {
+			first := owners(t, tc.build(services), 1000)
+
+			// rebuilt with services reordered, owners are decided by service ids only
+			reversed := make([]*registry.Service, 0, len(services))
+			for i := len(services) - 1; i >= 0; i-- {
+				reversed = append(reversed, services[i])
+			}
+
+			second := owners(t, tc.build(reversed), 1000)
+			for key, id := range first {
+				if second[key] != id {
+					t.Fatalf("owner of %s changed from %s to %s", key, id, second[key])
+				}
+			}
+		})
+	}
+}
+
+func TestHashPickersMinimalDisruption(t *testing.T) {
+	const keys = 20000
+
+	services := newHashServices(100, 100, 100, 100, 100, 100, 100, 100, 100, 100)
+
+	cases := []struct {
+		name   string
+		before []*registry.Service
+		after  []*registry.Service
+		// id of service removed or added
+		changed string
+	}{
+		{
+			name:    "remove",
+			before:  services,
+			after:   append(append([]*registry.Service{}, services[:3]...), services[4:]...),
+			changed: services[3].ID,
+		},
+		{
+			name:    "add",
+			before:  services[:9],
+			after:   services,
+			changed: services[9].ID,
+		},
+	}
+
+	for _, tb := range hashBuilders {
+		for _, tc := range cases {
+			t.Run(tb.name+"/"+tc.name, func(t *testing.T) {
+				before := owners(t, tb.build(tc.before), keys)
+				after := owners(t, tb.build(tc.after), keys)
+
+				moved := 0
+				for key, id := range before {
+					// keys of the removed service, or taken by the added one, must move
+					if id == tc.changed || after[key] == tc.changed {
+						continue
+					}
+					if after[key] != id {
+						moved++
+					}
+				}
+
+				if ratio := float64(moved) / keys; ratio > tb.maxDisruption {
+					t.Fatalf("%.4f of keys moved between unchanged services, expected at most %.4f", ratio, tb.maxDisruption)
+				}
+			})
+		}
+	}
+}
+
+func TestHashPickersWeight(t *testing.T) {
+	const keys = 20000
+
+	cases := []struct {
+		name    string
+		weights []int32
+	}{
+		{name: "equal", weights: []int32{100, 100, 100, 100}},
+		{name: "triple", weights: []int32{100, 300}},
+		{name: "mixed", weights: []int32{50, 100, 200, 400}},
+	}
+
+	for _, tb := range hashBuilders {
+		for _, tc := range cases {
+			t.Run(tb.name+"/"+tc.name, func(t *testing.T) {
+				services := newHashServices(tc.weights...)
+
+				var total int32
+				for _, weight := range tc.weights {
+					total += weight
+				}
+
+				counts := make(map[string]int)
+				for _, id := range owners(t, tb.build(services), keys) {
+					counts[id]++
+				}
+
+				for i, service := range services {
+					expected := float64(tc.weights[i]) / float64(total)
+					actual := float64(counts[service.ID]) / keys
+
+					if math.Abs(actual-expected) > 0.05 {
+						t.Errorf("%s owns %.3f of keys, expected %.3f", service.ID, actual, expected)
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestBoundedRingHashLoads(t *testing.T) {
+	cases := []struct {
+		name     string
+		factor   float64
+		services int
+		inflight int
+	}{
+		{name: "default", factor: DefaultBoundedLoadFactor, services: 4, inflight: 100},
+		{name: "loose", factor: 2, services: 5, inflight: 50},
+		{name: "invalid factor", factor: 0.5, services: 3, inflight: 30},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			factor := tc.factor
+			if factor < 1 {
+				factor = DefaultBoundedLoadFactor
+			}
+
+			picker := NewBoundedRingHashBuilder(tc.factor).Build(newHashServices(make([]int32, tc.services)...))
+
+			// a hot key is spilled over to the next services once its owner is full
+			loads := make(map[string]int)
+			var dones []DoneFunc
+			for i := 0; i < tc.inflight; i++ {
+				service, done, err := picker.Pick(PickInfo{Key: "hot"})
+				if err != nil {
+					t.Fatalf("Pick(): %v", err)
+				}
+
+				loads[service.ID]++
+				dones = append(dones, done)
+			}
+
+			capacity := int(math.Ceil(factor * float64(tc.inflight) / float64(tc.services)))
+			for id, load := range loads {
+				if load > capacity {
+					t.Errorf("%s has %d inflight, expected at most %d", id, load, capacity)
+				}
+			}
+
+			for _, done := range dones {
+				done(nil)
+			}
+
+			// loads released, the key goes back to its owner
+			owner, done, _ := picker.Pick(PickInfo{Key: "hot"})
+			done(nil)
+
+			expected, _, _ := NewRingHash(newHashServices(make([]int32, tc.services)...)).Pick(PickInfo{Key: "hot"})
+			if owner.ID != expected.ID {
+				t.Fatalf("owner of released key is %s, expected %s", owner.ID, expected.ID)
+			}
+		})
+	}
+}
@@ -0,0 +1,179 @@
+package balancer
+
+import (
+	"sync"
+	"time"
+
+	"github.com/leon-gopher/discovery/logger"
+	"github.com/leon-gopher/discovery/registry"
+)
+
+type LocalityOption func(*localityOption)
+
+type localityOption struct {
+	zone  string
+	cloud string
+
+	// minimal healthy percent of local pool, spills over to the next level when dropped below
+	minHealthyPercent int
+	calmInterval      time.Duration
+
+	logger logger.Structured
+}
+
+// WithLocalZone sets zone of the caller, default to registry.LocalZone.
+func WithLocalZone(zone string) LocalityOption {
+	return func(o *localityOption) {
+		o.zone = zone
+	}
+}
+
+// WithLocalCloud sets cloud of the caller, default to registry.LocalCloud.
+func WithLocalCloud(cloud string) LocalityOption {
+	return func(o *localityOption) {
+		o.cloud = cloud
+	}
+}
+
+// WithLocalityLogger writes spillover logs to l, default to the global logger. Use Registry.Logger to share the one of
+// the Registry.
+func WithLocalityLogger(l logger.Structured) LocalityOption {
+	return func(o *localityOption) {
+		if l != nil {
+			o.logger = l
+		}
+	}
+}
+
+// WithMinHealthyPercent sets threshold of spillover in [0, 100], default to DefaultMinHealthyPercent.
+func WithMinHealthyPercent(percent int) LocalityOption {
+	return func(o *localityOption) {
+		if percent < 0 {
+			percent = 0
+		}
+		if percent > 100 {
+			percent = 100
+		}
+
+		o.minHealthyPercent = percent
+	}
+}
+
+// WithLocalityCalmInterval sets how long a shrunk local pool is treated as the new peak, default to DefaultLocalityCalmInterval.
+func WithLocalityCalmInterval(interval time.Duration) LocalityOption {
+	return func(o *localityOption) {
+		o.calmInterval = interval
+	}
+}
+
+type localityPeak struct {
+	total int
+	since time.Time
+}
+
+// localityLevel is a local pool of services, e.g. the zone pool of a service key.
+type localityLevel struct {
+	key   registry.ServiceKey
+	level string
+}
+
+// locality builds pickers preferring services in the same zone of caller, then the same cloud, then anything else.
+//
+// Healthy share of a local pool is count of discoverable services over the peak count seen, like passingOnlyDegrade of
+// consul. The peak is lowered after the pool has been shrunk for calm interval, so scaling in does not spill forever.
+type locality struct {
+	builder Builder
+	opts    *localityOption
+
+	mux   sync.Mutex
+	peaks map[localityLevel]*localityPeak
+}
+
+// NewLocalityBuilder creates a Builder filters services by locality before building with builder given. It's
+// registered as Locality with weighted round robin, register others for using by name, e.g.
+// Register("locality_p2c", NewLocalityBuilder(Get(P2C))).
+func NewLocalityBuilder(builder Builder, opts ...LocalityOption) Builder {
+	o := &localityOption{
+		zone:              registry.LocalZone(),
+		cloud:             registry.LocalCloud(),
+		minHealthyPercent: DefaultMinHealthyPercent,
+		calmInterval:      DefaultLocalityCalmInterval,
+		logger:            logger.Global(),
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	return &locality{
+		builder: builder,
+		opts:    o,
+		peaks:   make(map[localityLevel]*localityPeak),
+	}
+}
+
+// Build shares peaks of local pools among all services, use BuildKey for pickers of different services.
+func (l *locality) Build(services []*registry.Service) Picker {
+	return l.BuildKey(registry.ServiceKey{}, services)
+}
+
+// BuildKey builds a picker with peaks of local pools kept per key.
+func (l *locality) BuildKey(key registry.ServiceKey, services []*registry.Service) Picker {
+	var zones, clouds []*registry.Service
+	for _, service := range services {
+		if len(l.opts.cloud) > 0 && service.Meta[registry.MetaCloud] != l.opts.cloud {
+			continue
+		}
+		clouds = append(clouds, service)
+
+		if len(l.opts.zone) > 0 && service.Meta[registry.MetaZone] == l.opts.zone {
+			zones = append(zones, service)
+		}
+	}
+
+	now := time.Now()
+
+	l.mux.Lock()
+	defer l.mux.Unlock()
+
+	if len(l.opts.zone) > 0 && l.isHealthy(localityLevel{key, "zone"}, len(zones), now) {
+		return l.builder.Build(zones)
+	}
+
+	if len(l.opts.cloud) > 0 && l.isHealthy(localityLevel{key, "cloud"}, len(clouds), now) {
+		return l.builder.Build(clouds)
+	}
+
+	if len(zones) > 0 || len(clouds) > 0 {
+		l.opts.logger.Warn("balancer.Locality() spillover", "service", key.ToString(), "zone", len(zones), "cloud", len(clouds), "total", len(services))
+	}
+
+	return l.builder.Build(services)
+}
+
+// isHealthy returns true if the count of local pool is not less than min healthy percent of its peak.
+func (l *locality) isHealthy(pool localityLevel, count int, now time.Time) bool {
+	peak, ok := l.peaks[pool]
+	if !ok {
+		peak = &localityPeak{}
+		l.peaks[pool] = peak
+	}
+
+	switch {
+	case count >= peak.total:
+		peak.total = count
+		peak.since = time.Time{}
+
+	case peak.since.IsZero():
+		peak.since = now
+
+	case now.Sub(peak.since) >= l.opts.calmInterval:
+		peak.total = count
+		peak.since = time.Time{}
+	}
+
+	if count <= 0 {
+		return false
+	}
+
+	return count*100 >= peak.total*l.opts.minHealthyPercent
+}
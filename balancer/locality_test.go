@@ -0,0 +1,97 @@
+package balancer
+
+import (
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/leon-gopher/discovery/registry"
+)
+
+// pickedIDs picks n times and returns sorted ids picked.
+func pickedIDs(t *testing.T, picker Picker, n int) string {
+	t.Helper()
+
+	seen := make(map[string]bool)
+	for i := 0; i < n; i++ {
+		service, done, err := picker.Pick(PickInfo{})
+		if err != nil {
+			t.Fatalf("Pick(): %v", err)
+		}
+		done(nil)
+
+		seen[service.ID] = true
+	}
+
+	ids := make([]string, 0, len(seen))
+	for id := range seen {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	return strings.Join(ids, ",")
+}
+
+func localityServices(zones ...string) []*registry.Service {
+	services := make([]*registry.Service, 0, len(zones))
+	for i, zone := range zones {
+		services = append(services, &registry.Service{
+			ID:     zone + "-" + string(rune('a'+i)),
+			Name:   "svc",
+			Weight: 100,
+			Meta:   map[string]string{registry.MetaZone: zone, registry.MetaCloud: "aliyun"},
+		})
+	}
+
+	return services
+}
+
+func TestLocalityBuildKey(t *testing.T) {
+	builder := NewLocalityBuilder(BuilderFunc(NewRoundRobin),
+		WithLocalZone("z1"), WithLocalCloud("aliyun"), WithMinHealthyPercent(50), WithLocalityCalmInterval(time.Hour))
+
+	lb := builder.(KeyBuilder)
+	v1 := registry.NewServiceKey("svc", []string{"v1"}, "")
+	v2 := registry.NewServiceKey("svc", []string{"v2"}, "")
+
+	steps := []struct {
+		key      registry.ServiceKey
+		services []*registry.Service
+		picked   string
+	}{
+		// local zone preferred
+		{key: v1, services: localityServices("z1", "z1", "z1", "z1", "z2"), picked: "z1-a,z1-b,z1-c,z1-d"},
+		// another key of the same name has its own peak of 1
+		{key: v2, services: localityServices("z1", "z2"), picked: "z1-a"},
+		// 1 of peak 4 is below 50%, spills over to the cloud
+		{key: v1, services: localityServices("z1", "z2", "z2"), picked: "z1-a,z2-b,z2-c"},
+		// unaffected by the shrunk pool of v1
+		{key: v2, services: localityServices("z1", "z2"), picked: "z1-a"},
+		// back to the peak
+		{key: v1, services: localityServices("z1", "z1", "z2"), picked: "z1-a,z1-b"},
+	}
+
+	for i, step := range steps {
+		if picked := pickedIDs(t, lb.BuildKey(step.key, step.services), 20); picked != step.picked {
+			t.Fatalf("step %d: picked %s, expected %s", i, picked, step.picked)
+		}
+	}
+
+	// empty services never panic nor pick
+	if _, _, err := lb.BuildKey(v1, nil).Pick(PickInfo{}); err == nil {
+		t.Fatal("Pick() of empty services should fail")
+	}
+}
+
+func TestLocalityRegistered(t *testing.T) {
+	builder, ok := Get(Locality).(KeyBuilder)
+	if !ok {
+		t.Fatalf("%s is not registered as KeyBuilder", Locality)
+	}
+
+	service := &registry.Service{ID: "a", Name: "svc", Weight: 100}
+	if picked := pickedIDs(t, builder.BuildKey(registry.NewServiceKey("svc", nil, ""), []*registry.Service{service}), 3); picked != "a" {
+		t.Fatalf("picked %s, expected a", picked)
+	}
+}
@@ -0,0 +1,94 @@
+package balancer
+
+import (
+	"sort"
+
+	"github.com/leon-gopher/discovery/errors"
+	"github.com/leon-gopher/discovery/registry"
+)
+
+// maglev implements Maglev hashing of google with weighted table population, services are hashed by ID.
+type maglev struct {
+	services []*registry.Service
+	table    []int
+}
+
+// NewMaglev creates a Picker picks services by Maglev hashing of PickInfo.Key.
+func NewMaglev(services []*registry.Service) Picker {
+	// population depends on order of services, sort by ID so that the table is decided by services only
+	services = append([]*registry.Service(nil), services...)
+	sort.Slice(services, func(i, j int) bool {
+		return services[i].ID < services[j].ID
+	})
+
+	p := &maglev{
+		services: services,
+	}
+
+	if len(services) > 0 {
+		p.table = maglevPopulate(services, DefaultMaglevTableSize)
+	}
+
+	return p
+}
+
+func (p *maglev) Pick(info PickInfo) (*registry.Service, DoneFunc, error) {
+	if len(p.table) == 0 {
+		return nil, nil, errors.Wrap(errors.ErrNotFound)
+	}
+
+	idx := p.table[hashKey(info.Key)%uint64(len(p.table))]
+
+	return p.services[idx], noopDone, nil
+}
+
+func maglevPopulate(services []*registry.Service, size uint64) []int {
+	var maxWeight int64
+
+	offsets := make([]uint64, len(services))
+	skips := make([]uint64, len(services))
+	for i, service := range services {
+		offsets[i] = hashString("offset#"+service.ID) % size
+		skips[i] = hashString("skip#"+service.ID)%(size-1) + 1
+
+		if weight := weightOf(service); weight > maxWeight {
+			maxWeight = weight
+		}
+	}
+
+	table := make([]int, size)
+	for i := range table {
+		table[i] = -1
+	}
+
+	nexts := make([]uint64, len(services))
+	targets := make([]float64, len(services))
+	filled := make([]float64, len(services))
+
+	for n := uint64(0); n < size; {
+		for i, service := range services {
+			// each service takes turns in proportion to its weight
+			targets[i] += float64(weightOf(service)) / float64(maxWeight)
+			if filled[i] >= targets[i] {
+				continue
+			}
+
+			c := (offsets[i] + nexts[i]*skips[i]) % size
+			for table[c] >= 0 {
+				nexts[i]++
+				c = (offsets[i] + nexts[i]*skips[i]) % size
+			}
+
+			table[c] = i
+			nexts[i]++
+			filled[i]++
+			n++
+
+			if n == size {
+				break
+			}
+		}
+	}
+
+	return table
+}
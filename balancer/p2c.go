@@ -0,0 +1,70 @@
+package balancer
+
+import (
+	"math/rand"
+	"sync/atomic"
+
+	"github.com/leon-gopher/discovery/errors"
+	"github.com/leon-gopher/discovery/registry"
+)
+
+type p2cNode struct {
+	service  *registry.Service
+	weight   int64
+	inflight int64
+}
+
+// p2c implements power of two choices with the least inflight requests by weight.
+type p2c struct {
+	nodes []*p2cNode
+}
+
+// NewP2C creates a Picker picks the less loaded one of two random services.
+func NewP2C(services []*registry.Service) Picker {
+	p := &p2c{
+		nodes: make([]*p2cNode, 0, len(services)),
+	}
+
+	for _, service := range services {
+		p.nodes = append(p.nodes, &p2cNode{
+			service: service,
+			weight:  weightOf(service),
+		})
+	}
+
+	return p
+}
+
+func (p *p2c) Pick(PickInfo) (*registry.Service, DoneFunc, error) {
+	var node *p2cNode
+
+	switch len(p.nodes) {
+	case 0:
+		return nil, nil, errors.Wrap(errors.ErrNotFound)
+
+	case 1:
+		node = p.nodes[0]
+
+	default:
+		i := rand.Intn(len(p.nodes))
+		j := rand.Intn(len(p.nodes) - 1)
+		if j >= i {
+			j++
+		}
+
+		a, b := p.nodes[i], p.nodes[j]
+
+		// compare inflight/weight without division
+		if atomic.LoadInt64(&a.inflight)*b.weight <= atomic.LoadInt64(&b.inflight)*a.weight {
+			node = a
+		} else {
+			node = b
+		}
+	}
+
+	atomic.AddInt64(&node.inflight, 1)
+
+	return node.service, func(error) {
+		atomic.AddInt64(&node.inflight, -1)
+	}, nil
+}
@@ -0,0 +1,74 @@
+package balancer
+
+import (
+	"sort"
+	"strconv"
+
+	"github.com/leon-gopher/discovery/errors"
+	"github.com/leon-gopher/discovery/registry"
+)
+
+type ringNode struct {
+	hash  uint64
+	index int
+}
+
+// ringHash implements consistent hashing with virtual nodes in proportion to weight. Virtual nodes are
+// hashed by service ID, so only keys owned by changed services move when services updated.
+type ringHash struct {
+	services []*registry.Service
+	ring     []ringNode
+}
+
+// NewRingHash creates a Picker picks services by consistent hashing of PickInfo.Key.
+func NewRingHash(services []*registry.Service) Picker {
+	return newRingHash(services)
+}
+
+func newRingHash(services []*registry.Service) *ringHash {
+	p := &ringHash{
+		services: services,
+	}
+
+	for i, service := range services {
+		replicas := int(weightOf(service) * DefaultRingReplicas / DefaultBaseWeight)
+		if replicas <= 0 {
+			replicas = 1
+		}
+
+		for j := 0; j < replicas; j++ {
+			p.ring = append(p.ring, ringNode{
+				hash:  hashString(service.ID + "#" + strconv.Itoa(j)),
+				index: i,
+			})
+		}
+	}
+
+	sort.Slice(p.ring, func(i, j int) bool {
+		return p.ring[i].hash < p.ring[j].hash
+	})
+
+	return p
+}
+
+func (p *ringHash) Pick(info PickInfo) (*registry.Service, DoneFunc, error) {
+	if len(p.ring) == 0 {
+		return nil, nil, errors.Wrap(errors.ErrNotFound)
+	}
+
+	node := p.ring[p.search(hashKey(info.Key))]
+
+	return p.services[node.index], noopDone, nil
+}
+
+// search returns index of the first virtual node clockwise from hash.
+func (p *ringHash) search(hash uint64) int {
+	idx := sort.Search(len(p.ring), func(i int) bool {
+		return p.ring[i].hash >= hash
+	})
+	if idx == len(p.ring) {
+		idx = 0
+	}
+
+	return idx
+}
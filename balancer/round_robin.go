@@ -0,0 +1,38 @@
+package balancer
+
+import (
+	"math/rand"
+	"sync/atomic"
+
+	"github.com/leon-gopher/discovery/errors"
+	"github.com/leon-gopher/discovery/registry"
+)
+
+type roundRobin struct {
+	services []*registry.Service
+	next     uint32
+}
+
+// NewRoundRobin creates a Picker picks services in turn.
+func NewRoundRobin(services []*registry.Service) Picker {
+	p := &roundRobin{
+		services: services,
+	}
+
+	// start with a random index to avoid all clients hitting the first one
+	if len(services) > 0 {
+		p.next = uint32(rand.Intn(len(services)))
+	}
+
+	return p
+}
+
+func (p *roundRobin) Pick(PickInfo) (*registry.Service, DoneFunc, error) {
+	if len(p.services) == 0 {
+		return nil, nil, errors.Wrap(errors.ErrNotFound)
+	}
+
+	idx := atomic.AddUint32(&p.next, 1) % uint32(len(p.services))
+
+	return p.services[idx], noopDone, nil
+}
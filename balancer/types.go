@@ -0,0 +1,55 @@
+package balancer
+
+import (
+	"context"
+
+	"github.com/leon-gopher/discovery/registry"
+)
+
+// Resolver resolves and watches services for a Balancer, *discovery.Registry implements it. It keeps the package
+// free of registry adapters.
+type Resolver interface {
+	LookupServicesContext(ctx context.Context, name string, opts ...registry.DiscoveryOption) ([]*registry.Service, error)
+	WithWatcherFunc(key registry.ServiceKey, w registry.Watcher) (cancel func())
+}
+
+// PickInfo contains information of the request for picking.
+type PickInfo struct {
+	// Key is used by hash based pickers for sticky routing.
+	Key string
+}
+
+// DoneFunc is called with the result of the request when it's finished.
+type DoneFunc func(err error)
+
+// Picker picks a service instance from a snapshot of services, it must be thread safe.
+type Picker interface {
+	Pick(info PickInfo) (*registry.Service, DoneFunc, error)
+}
+
+// Builder creates a Picker with a snapshot of services.
+type Builder interface {
+	Build(services []*registry.Service) Picker
+}
+
+// KeyBuilder is implemented by Builders keeping state per service key, e.g. the locality builder. Balancer calls
+// BuildKey instead of Build for them.
+type KeyBuilder interface {
+	Builder
+	BuildKey(key registry.ServiceKey, services []*registry.Service) Picker
+}
+
+// BuildKey creates a Picker of services of the key with builder, BuildKey is preferred if builder is a KeyBuilder.
+func BuildKey(builder Builder, key registry.ServiceKey, services []*registry.Service) Picker {
+	if kb, ok := builder.(KeyBuilder); ok {
+		return kb.BuildKey(key, services)
+	}
+
+	return builder.Build(services)
+}
+
+type BuilderFunc func(services []*registry.Service) Picker
+
+func (f BuilderFunc) Build(services []*registry.Service) Picker {
+	return f(services)
+}
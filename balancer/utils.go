@@ -0,0 +1,46 @@
+package balancer
+
+import (
+	"hash/fnv"
+	"math/rand"
+
+	"github.com/leon-gopher/discovery/registry"
+)
+
+// weightOf returns weight of the service, it's 1 at least.
+func weightOf(service *registry.Service) int64 {
+	if service.Weight <= 0 {
+		return 1
+	}
+
+	return int64(service.Weight)
+}
+
+func noopDone(error) {}
+
+// hashKey returns hash of key, it's random for empty key to spread requests.
+func hashKey(key string) uint64 {
+	if len(key) == 0 {
+		return rand.Uint64()
+	}
+
+	return hashString(key)
+}
+
+func hashString(s string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(s))
+
+	return mix64(h.Sum64())
+}
+
+// mix64 is the finalizer of murmur3, fnv has poor avalanche for short and similar strings.
+func mix64(h uint64) uint64 {
+	h ^= h >> 33
+	h *= 0xff51afd7ed558ccd
+	h ^= h >> 33
+	h *= 0xc4ceb9fe1a85ec53
+	h ^= h >> 33
+
+	return h
+}
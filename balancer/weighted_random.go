@@ -0,0 +1,43 @@
+package balancer
+
+import (
+	"math/rand"
+	"sort"
+
+	"github.com/leon-gopher/discovery/errors"
+	"github.com/leon-gopher/discovery/registry"
+)
+
+type weightedRandom struct {
+	services []*registry.Service
+	sums     []int64
+	total    int64
+}
+
+// NewWeightedRandom creates a Picker picks services randomly in proportion to weight.
+func NewWeightedRandom(services []*registry.Service) Picker {
+	p := &weightedRandom{
+		services: services,
+		sums:     make([]int64, len(services)),
+	}
+
+	for i, service := range services {
+		p.total += weightOf(service)
+		p.sums[i] = p.total
+	}
+
+	return p
+}
+
+func (p *weightedRandom) Pick(PickInfo) (*registry.Service, DoneFunc, error) {
+	if len(p.services) == 0 {
+		return nil, nil, errors.Wrap(errors.ErrNotFound)
+	}
+
+	n := rand.Int63n(p.total)
+	idx := sort.Search(len(p.sums), func(i int) bool {
+		return p.sums[i] > n
+	})
+
+	return p.services[idx], noopDone, nil
+}
@@ -0,0 +1,62 @@
+package balancer
+
+import (
+	"sync"
+
+	"github.com/leon-gopher/discovery/errors"
+	"github.com/leon-gopher/discovery/registry"
+)
+
+type weightedNode struct {
+	service *registry.Service
+	weight  int64
+	current int64
+}
+
+// weightedRoundRobin implements smooth weighted round robin of nginx.
+type weightedRoundRobin struct {
+	mux   sync.Mutex
+	nodes []*weightedNode
+	total int64
+}
+
+// NewWeightedRoundRobin creates a Picker picks services by smooth weighted round robin.
+func NewWeightedRoundRobin(services []*registry.Service) Picker {
+	p := &weightedRoundRobin{
+		nodes: make([]*weightedNode, 0, len(services)),
+	}
+
+	for _, service := range services {
+		weight := weightOf(service)
+
+		p.nodes = append(p.nodes, &weightedNode{
+			service: service,
+			weight:  weight,
+		})
+		p.total += weight
+	}
+
+	return p
+}
+
+func (p *weightedRoundRobin) Pick(PickInfo) (*registry.Service, DoneFunc, error) {
+	if len(p.nodes) == 0 {
+		return nil, nil, errors.Wrap(errors.ErrNotFound)
+	}
+
+	p.mux.Lock()
+	defer p.mux.Unlock()
+
+	var best *weightedNode
+	for _, node := range p.nodes {
+		node.current += node.weight
+
+		if best == nil || node.current > best.current {
+			best = node
+		}
+	}
+
+	best.current -= p.total
+
+	return best.service, noopDone, nil
+}
@@ -0,0 +1,319 @@
+package discovery
+
+import (
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/leon-gopher/discovery/consul"
+	"github.com/leon-gopher/discovery/dumper"
+	"github.com/leon-gopher/discovery/errors"
+	"github.com/leon-gopher/discovery/file"
+	"github.com/leon-gopher/discovery/registry"
+)
+
+// Config describes a whole Registry, see LoadConfig for the format.
+type Config struct {
+	// Discoveries in order of lookup, the later ones are used for fallback.
+	Discoveries []RegistryType `yaml:"discoveries"`
+	// Registrators to register services with, only RegistryConsul supported.
+	Registrators []RegistryType `yaml:"registrators"`
+	// Consul is required if RegistryConsul used.
+	Consul *ConsulConfig `yaml:"consul"`
+	// Dump is used by consul for storing and by file discovery for loading, default to DumpConfig{}.
+	Dump *DumpConfig `yaml:"dump"`
+	// FailType is "failback" or "failfast", default to "failback".
+	FailType string `yaml:"fail_type"`
+	// Bootstrap is expected instances of services by name, fallback is triggered below it.
+	Bootstrap map[string]int `yaml:"bootstrap"`
+}
+
+// ConsulConfig defines settings of consul adapter, zero values are left to defaults of consul.New.
+type ConsulConfig struct {
+	Addr        string `yaml:"addr"`
+	Stale       *bool  `yaml:"stale"`
+	PassingOnly *bool  `yaml:"passing_only"`
+	// DegradeThreshold in [0, 1], 0 disables degrade.
+	DegradeThreshold *float32      `yaml:"degrade_threshold"`
+	WatchWaitTime    time.Duration `yaml:"watch_wait"`
+	DumpInterval     time.Duration `yaml:"dump_interval"`
+}
+
+// DumpConfig defines local dump of services.
+type DumpConfig struct {
+	// Dir default to filepath.Join(os.TempDir(), DefaultTempDir), it's created if not existed.
+	Dir string `yaml:"dir"`
+	// Format default to dumper.FormatDiscovery.
+	Format dumper.FormatType `yaml:"format"`
+}
+
+var failTypes = map[string]FailType{
+	"":         FailBack,
+	"failback": FailBack,
+	"failfast": FailFast,
+}
+
+// LoadConfig reads and validates config from YAML file of path, JSON is accepted as well. For example:
+//
+//	discoveries: [consul, file]
+//	registrators: [consul]
+//	consul:
+//	  addr: http://127.0.0.1:8500
+//	  degrade_threshold: 0.8
+//	  watch_wait: 3m
+//	  dump_interval: 3h
+//	dump:
+//	  dir: /tmp/discovery-local
+//	  format: discovery
+//	fail_type: failback
+//	bootstrap:
+//	  user-service: 3
+func LoadConfig(path string) (*Config, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err)
+	}
+
+	cfg := new(Config)
+
+	err = yaml.UnmarshalStrict(data, cfg)
+	if err != nil {
+		return nil, errors.Errorf("%s: %v: %w", path, err, errors.ErrInvalidConfig)
+	}
+
+	err = cfg.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+// Validate checks cfg, returned errors match errors.ErrInvalidConfig.
+func (cfg *Config) Validate() error {
+	if len(cfg.Discoveries) == 0 {
+		return errors.Errorf("discoveries: empty: %w", errors.ErrInvalidConfig)
+	}
+	if err := validateTypes("discoveries", cfg.Discoveries); err != nil {
+		return err
+	}
+	if err := validateTypes("registrators", cfg.Registrators); err != nil {
+		return err
+	}
+	for i, rtype := range cfg.Registrators {
+		if rtype != RegistryConsul {
+			return errors.Errorf("registrators[%d]: %s does not support registration: %w", i, rtype, errors.ErrInvalidConfig)
+		}
+	}
+
+	if cfg.uses(RegistryConsul) {
+		if cfg.Consul == nil {
+			return errors.Errorf("consul: required by %s: %w", RegistryConsul, errors.ErrInvalidConfig)
+		}
+		if err := cfg.Consul.validate(); err != nil {
+			return err
+		}
+	}
+
+	if cfg.Dump != nil && len(cfg.Dump.Format) > 0 && !cfg.Dump.Format.IsValid() {
+		return errors.Errorf("dump.format: invalid %q, it could be [%s|%s]: %w", cfg.Dump.Format, dumper.FormatConsul, dumper.FormatDiscovery, errors.ErrInvalidConfig)
+	}
+
+	if _, ok := failTypes[strings.ToLower(cfg.FailType)]; !ok {
+		return errors.Errorf("fail_type: invalid %q, it could be [failback|failfast]: %w", cfg.FailType, errors.ErrInvalidConfig)
+	}
+
+	for name, expected := range cfg.Bootstrap {
+		if len(name) == 0 {
+			return errors.Errorf("bootstrap: empty service name: %w", errors.ErrInvalidConfig)
+		}
+		if expected < 0 {
+			return errors.Errorf("bootstrap.%s: negative instances %d: %w", name, expected, errors.ErrInvalidConfig)
+		}
+	}
+
+	return nil
+}
+
+func (cfg *Config) uses(rtype RegistryType) bool {
+	for _, t := range cfg.Discoveries {
+		if t == rtype {
+			return true
+		}
+	}
+	for _, t := range cfg.Registrators {
+		if t == rtype {
+			return true
+		}
+	}
+
+	return false
+}
+
+func validateTypes(field string, types []RegistryType) error {
+	seen := make(map[RegistryType]bool)
+	for i, rtype := range types {
+		if !rtype.IsValid() {
+			return errors.Errorf("%s[%d]: invalid %q, it could be [%s|%s]: %w", field, i, rtype, RegistryConsul, RegistryFile, errors.ErrInvalidConfig)
+		}
+		if seen[rtype] {
+			return errors.Errorf("%s[%d]: duplicated %s: %w", field, i, rtype, errors.ErrInvalidConfig)
+		}
+		seen[rtype] = true
+	}
+
+	return nil
+}
+
+func (cfg *ConsulConfig) validate() error {
+	if len(cfg.Addr) == 0 {
+		return errors.Errorf("consul.addr: empty: %w", errors.ErrInvalidConfig)
+	}
+
+	uri, err := url.Parse(cfg.Addr)
+	if err != nil || len(uri.Scheme) == 0 || len(uri.Host) == 0 {
+		return errors.Errorf("consul.addr: invalid %q, it should be like http://127.0.0.1:8500: %w", cfg.Addr, errors.ErrInvalidConfig)
+	}
+
+	if cfg.DegradeThreshold != nil && (*cfg.DegradeThreshold < 0 || *cfg.DegradeThreshold > 1) {
+		return errors.Errorf("consul.degrade_threshold: %v out of [0, 1]: %w", *cfg.DegradeThreshold, errors.ErrInvalidConfig)
+	}
+	if cfg.WatchWaitTime < 0 {
+		return errors.Errorf("consul.watch_wait: negative %v: %w", cfg.WatchWaitTime, errors.ErrInvalidConfig)
+	}
+	if cfg.DumpInterval < 0 {
+		return errors.Errorf("consul.dump_interval: negative %v: %w", cfg.DumpInterval, errors.ErrInvalidConfig)
+	}
+
+	return nil
+}
+
+func (cfg *ConsulConfig) options() []consul.ConsulOption {
+	var opts []consul.ConsulOption
+	if cfg.Stale != nil {
+		opts = append(opts, consul.WithStale(*cfg.Stale))
+	}
+	if cfg.PassingOnly != nil {
+		opts = append(opts, consul.WithPassingOnly(*cfg.PassingOnly))
+	}
+	if cfg.DegradeThreshold != nil {
+		opts = append(opts, consul.WithDegrade(*cfg.DegradeThreshold))
+	}
+	if cfg.WatchWaitTime > 0 {
+		opts = append(opts, consul.WithWatchWaitTime(cfg.WatchWaitTime))
+	}
+	if cfg.DumpInterval > 0 {
+		opts = append(opts, consul.WithDumpInterval(cfg.DumpInterval))
+	}
+
+	return opts
+}
+
+func (cfg *Config) dumper() (dumper.Dumper, error) {
+	dcfg := cfg.Dump
+	if dcfg == nil {
+		dcfg = new(DumpConfig)
+	}
+
+	dir := dcfg.Dir
+	if len(dir) == 0 {
+		dir = filepath.Join(os.TempDir(), DefaultTempDir)
+	}
+
+	err := os.MkdirAll(dir, 0755)
+	if err != nil {
+		return nil, errors.Wrap(err)
+	}
+
+	format := dcfg.Format
+	if len(format) == 0 {
+		format = dumper.FormatDiscovery
+	}
+
+	return dumper.New(dumper.WithLocalDir(dir), dumper.WithFormat(format))
+}
+
+// NewRegistryFromConfig creates a new *Registry described by cfg, opts are applied after the config for settings
+// which cannot be declared. WithLogger, WithMetrics, WithHooks and WithTracerProvider are forwarded to the consul
+// adapter as well.
+func NewRegistryFromConfig(cfg *Config, opts ...RegistryOption) (*Registry, error) {
+	if cfg == nil {
+		return nil, errors.Wrap(errors.ErrNilConfig)
+	}
+
+	err := cfg.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	var dp dumper.Dumper
+	if cfg.Dump != nil || cfg.uses(RegistryFile) {
+		dp, err = cfg.dumper()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	adapters := make(map[RegistryType]interface{})
+	if cfg.uses(RegistryConsul) {
+		copts := cfg.Consul.options()
+		if dp != nil {
+			copts = append(copts, consul.WithDumper(dp))
+		}
+		copts = append(copts, forwardOptions(opts)...)
+
+		adapter, err := consul.New(cfg.Consul.Addr, copts...)
+		if err != nil {
+			return nil, errors.Wrap(err)
+		}
+
+		adapters[RegistryConsul] = adapter
+	}
+	if cfg.uses(RegistryFile) {
+		adapters[RegistryFile] = file.New(dp)
+	}
+
+	ropts := []RegistryOption{
+		WithFailType(failTypes[strings.ToLower(cfg.FailType)]),
+	}
+	for _, rtype := range cfg.Discoveries {
+		ropts = append(ropts, WithDiscoveries(adapters[rtype].(registry.Discovery)))
+	}
+	for _, rtype := range cfg.Registrators {
+		ropts = append(ropts, WithRegisters(adapters[rtype].(registry.Registrator)))
+	}
+	for name, expected := range cfg.Bootstrap {
+		ropts = append(ropts, WithBootstrapByName(name, expected))
+	}
+
+	return NewRegistry(append(ropts, opts...)...)
+}
+
+// forwardOptions converts settings shared by the Registry and consul adapter from opts.
+func forwardOptions(opts []RegistryOption) []consul.ConsulOption {
+	o := new(registryOption)
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	var copts []consul.ConsulOption
+	if o.logger != nil {
+		copts = append(copts, consul.WithLogger(o.logger))
+	}
+	if o.metrics != nil {
+		copts = append(copts, consul.WithMetrics(o.metrics))
+	}
+	if o.hooks != nil {
+		copts = append(copts, consul.WithHooks(o.hooks))
+	}
+	if o.tracerProvider != nil {
+		copts = append(copts, consul.WithTracerProvider(o.tracerProvider))
+	}
+
+	return copts
+}
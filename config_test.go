@@ -0,0 +1,150 @@
+package discovery
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/leon-gopher/discovery/dumper"
+	"github.com/leon-gopher/discovery/errors"
+)
+
+func loadConfig(t *testing.T, name, content string) (*Config, error) {
+	t.Helper()
+
+	dir, err := ioutil.TempDir("", "discovery-config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, name)
+	if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	return LoadConfig(path)
+}
+
+func TestLoadConfig(t *testing.T) {
+	cfg, err := loadConfig(t, "discovery.yaml", `
+discoveries: [consul, file]
+registrators: [consul]
+consul:
+  addr: http://127.0.0.1:8500
+  passing_only: false
+  degrade_threshold: 0.8
+  watch_wait: 3m
+  dump_interval: 3h
+dump:
+  dir: /tmp/discovery-local
+  format: consul
+fail_type: failfast
+bootstrap:
+  user-service: 3
+`)
+	if err != nil {
+		t.Fatalf("LoadConfig(): %v", err)
+	}
+
+	if len(cfg.Discoveries) != 2 || cfg.Discoveries[0] != RegistryConsul || cfg.Discoveries[1] != RegistryFile {
+		t.Errorf("Discoveries: %v", cfg.Discoveries)
+	}
+	if len(cfg.Registrators) != 1 || cfg.Registrators[0] != RegistryConsul {
+		t.Errorf("Registrators: %v", cfg.Registrators)
+	}
+	if cfg.Consul.Addr != "http://127.0.0.1:8500" || cfg.Consul.Stale != nil {
+		t.Errorf("Consul: %+v", cfg.Consul)
+	}
+	if cfg.Consul.PassingOnly == nil || *cfg.Consul.PassingOnly {
+		t.Errorf("Consul.PassingOnly: %v", cfg.Consul.PassingOnly)
+	}
+	if cfg.Consul.DegradeThreshold == nil || *cfg.Consul.DegradeThreshold != 0.8 {
+		t.Errorf("Consul.DegradeThreshold: %v", cfg.Consul.DegradeThreshold)
+	}
+	if cfg.Consul.WatchWaitTime != 3*time.Minute || cfg.Consul.DumpInterval != 3*time.Hour {
+		t.Errorf("Consul durations: %v, %v", cfg.Consul.WatchWaitTime, cfg.Consul.DumpInterval)
+	}
+	if cfg.Dump.Dir != "/tmp/discovery-local" || cfg.Dump.Format != dumper.FormatConsul {
+		t.Errorf("Dump: %+v", cfg.Dump)
+	}
+	if cfg.FailType != "failfast" || cfg.Bootstrap["user-service"] != 3 {
+		t.Errorf("FailType: %s, Bootstrap: %v", cfg.FailType, cfg.Bootstrap)
+	}
+
+	// JSON is a subset of YAML
+	cfg, err = loadConfig(t, "discovery.json", `{"discoveries": ["file"], "bootstrap": {"user-service": 1}}`)
+	if err != nil {
+		t.Fatalf("LoadConfig(json): %v", err)
+	}
+	if len(cfg.Discoveries) != 1 || cfg.Discoveries[0] != RegistryFile || cfg.Consul != nil {
+		t.Errorf("json: %+v", cfg)
+	}
+
+	// unknown fields are rejected
+	_, err = loadConfig(t, "unknown.yaml", "discoveries: [file]\nfailtype: failfast\n")
+	if !errors.Is(err, errors.ErrInvalidConfig) {
+		t.Errorf("LoadConfig(unknown field): %v, expected %v", err, errors.ErrInvalidConfig)
+	}
+
+	_, err = LoadConfig(filepath.Join(os.TempDir(), "discovery-config-not-existed.yaml"))
+	if err == nil || errors.Is(err, errors.ErrInvalidConfig) {
+		t.Errorf("LoadConfig(not existed): %v", err)
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	const consul = "consul: {addr: 'http://127.0.0.1:8500'}\n"
+
+	cases := []struct {
+		name  string
+		yaml  string
+		valid bool
+	}{
+		{name: "file only", yaml: "discoveries: [file]", valid: true},
+		{name: "consul", yaml: "discoveries: [consul]\nregistrators: [consul]\n" + consul, valid: true},
+		{name: "upper case fail type", yaml: "discoveries: [file]\nfail_type: FailBack", valid: true},
+		{name: "zero bootstrap", yaml: "discoveries: [file]\nbootstrap: {user-service: 0}", valid: true},
+		{name: "degrade disabled", yaml: "discoveries: [consul]\nconsul: {addr: 'http://127.0.0.1:8500', degrade_threshold: 0}", valid: true},
+
+		{name: "no discoveries", yaml: "registrators: [consul]\n" + consul},
+		{name: "unknown discovery", yaml: "discoveries: [etcd]"},
+		{name: "duplicated discovery", yaml: "discoveries: [file, file]"},
+		{name: "duplicated registrator", yaml: "discoveries: [consul]\nregistrators: [consul, consul]\n" + consul},
+		{name: "file registrator", yaml: "discoveries: [file]\nregistrators: [file]"},
+		{name: "consul missing", yaml: "discoveries: [consul]"},
+		{name: "consul missing for registrator", yaml: "discoveries: [file]\nregistrators: [consul]"},
+		{name: "consul addr empty", yaml: "discoveries: [consul]\nconsul: {stale: true}"},
+		{name: "consul addr without scheme", yaml: "discoveries: [consul]\nconsul: {addr: '127.0.0.1:8500'}"},
+		{name: "degrade threshold above 1", yaml: "discoveries: [consul]\nconsul: {addr: 'http://127.0.0.1:8500', degrade_threshold: 1.5}"},
+		{name: "negative degrade threshold", yaml: "discoveries: [consul]\nconsul: {addr: 'http://127.0.0.1:8500', degrade_threshold: -0.1}"},
+		{name: "negative watch wait", yaml: "discoveries: [consul]\nconsul: {addr: 'http://127.0.0.1:8500', watch_wait: -1s}"},
+		{name: "negative dump interval", yaml: "discoveries: [consul]\nconsul: {addr: 'http://127.0.0.1:8500', dump_interval: -1s}"},
+		{name: "unknown dump format", yaml: "discoveries: [file]\ndump: {format: yaml}"},
+		{name: "unknown fail type", yaml: "discoveries: [file]\nfail_type: failover"},
+		{name: "empty bootstrap name", yaml: "discoveries: [file]\nbootstrap: {'': 1}"},
+		{name: "negative bootstrap", yaml: "discoveries: [file]\nbootstrap: {user-service: -1}"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := loadConfig(t, "discovery.yaml", tc.yaml)
+			if tc.valid {
+				if err != nil {
+					t.Fatalf("LoadConfig(): %v", err)
+				}
+				return
+			}
+
+			if !errors.Is(err, errors.ErrInvalidConfig) {
+				t.Fatalf("LoadConfig(): %v, expected %v", err, errors.ErrInvalidConfig)
+			}
+		})
+	}
+
+	if _, err := NewRegistryFromConfig(nil); !errors.Is(err, errors.ErrNilConfig) {
+		t.Fatalf("NewRegistryFromConfig(nil): %v, expected %v", err, errors.ErrNilConfig)
+	}
+}
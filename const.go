@@ -1,7 +1,25 @@
 package discovery
 
+import "time"
+
+const (
+	DefaultTempDir          = "discovery-local"
+	DefaultWatcherQueueSize = 1024
+)
+
 const (
-	DefaultTempDir = "discovery-local"
+	DefaultDrainReason       = "draining"
+	DefaultDrainGracePeriod  = 10 * time.Second
+	DefaultDrainPollInterval = 1 * time.Second
+
+	// DefaultHeartbeatInterval is used by Heartbeat with a non-positive interval.
+	DefaultHeartbeatInterval = 5 * time.Second
+
+	// DefaultReadinessTTL is TTL of readiness check, it is refreshed every third of it once ready.
+	DefaultReadinessTTL = 30 * time.Second
+
+	// DefaultWaitRetryInterval is interval of looking up again while no watch established, see WaitForServices.
+	DefaultWaitRetryInterval = 1 * time.Second
 )
 
 const (
@@ -0,0 +1,169 @@
+package consul
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/hashicorp/consul/api"
+	"github.com/leon-gopher/discovery/logger"
+)
+
+// fakeAgent serves the agent endpoints of services and checks used by the adapter.
+type fakeAgent struct {
+	*httptest.Server
+
+	mux      sync.Mutex
+	services map[string]*api.AgentService
+	checks   map[string]*api.AgentCheck
+	// registrations received in order
+	registered []*api.AgentServiceRegistration
+	// called before a registration applied if set
+	onRegister func(reg *api.AgentServiceRegistration)
+}
+
+func newFakeAgent(t *testing.T) *fakeAgent {
+	agent := &fakeAgent{
+		services: make(map[string]*api.AgentService),
+		checks:   make(map[string]*api.AgentCheck),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/agent/services", func(w http.ResponseWriter, r *http.Request) {
+		agent.mux.Lock()
+		defer agent.mux.Unlock()
+
+		json.NewEncoder(w).Encode(agent.services)
+	})
+	mux.HandleFunc("/v1/agent/checks", func(w http.ResponseWriter, r *http.Request) {
+		agent.mux.Lock()
+		defer agent.mux.Unlock()
+
+		json.NewEncoder(w).Encode(agent.checks)
+	})
+	mux.HandleFunc("/v1/agent/service/register", func(w http.ResponseWriter, r *http.Request) {
+		reg := new(api.AgentServiceRegistration)
+		if err := json.NewDecoder(r.Body).Decode(reg); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		agent.mux.Lock()
+		onRegister := agent.onRegister
+		agent.mux.Unlock()
+
+		if onRegister != nil {
+			onRegister(reg)
+		}
+
+		agent.register(reg)
+	})
+	mux.HandleFunc("/v1/agent/service/deregister/", func(w http.ResponseWriter, r *http.Request) {
+		agent.deregister(strings.TrimPrefix(r.URL.Path, "/v1/agent/service/deregister/"))
+	})
+	mux.HandleFunc("/v1/agent/check/update/", func(w http.ResponseWriter, r *http.Request) {
+		update := new(struct{ Status string })
+		if err := json.NewDecoder(r.Body).Decode(update); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		agent.mux.Lock()
+		defer agent.mux.Unlock()
+
+		check, ok := agent.checks[strings.TrimPrefix(r.URL.Path, "/v1/agent/check/update/")]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		check.Status = update.Status
+	})
+
+	agent.Server = httptest.NewServer(mux)
+
+	return agent
+}
+
+func (agent *fakeAgent) register(reg *api.AgentServiceRegistration) {
+	agent.mux.Lock()
+	defer agent.mux.Unlock()
+
+	agent.registered = append(agent.registered, reg)
+
+	service := &api.AgentService{
+		ID:      reg.ID,
+		Service: reg.Name,
+		Tags:    reg.Tags,
+		Meta:    reg.Meta,
+		Port:    reg.Port,
+		Address: reg.Address,
+	}
+	if reg.Weights != nil {
+		service.Weights = *reg.Weights
+	}
+	agent.services[reg.ID] = service
+
+	for id, check := range agent.checks {
+		if check.ServiceID == reg.ID {
+			delete(agent.checks, id)
+		}
+	}
+	for i, check := range reg.Checks {
+		id := check.CheckID
+		if len(id) == 0 {
+			id = "service:" + reg.ID + ":" + string(rune('1'+i))
+		}
+
+		status := check.Status
+		if len(status) == 0 {
+			status = api.HealthCritical
+		}
+
+		agent.checks[id] = &api.AgentCheck{CheckID: id, ServiceID: reg.ID, Status: status}
+	}
+}
+
+func (agent *fakeAgent) deregister(id string) {
+	agent.mux.Lock()
+	defer agent.mux.Unlock()
+
+	delete(agent.services, id)
+	for checkID, check := range agent.checks {
+		if check.ServiceID == id {
+			delete(agent.checks, checkID)
+		}
+	}
+}
+
+func (agent *fakeAgent) service(id string) *api.AgentService {
+	agent.mux.Lock()
+	defer agent.mux.Unlock()
+
+	return agent.services[id]
+}
+
+func (agent *fakeAgent) checkStatus(id string) string {
+	agent.mux.Lock()
+	defer agent.mux.Unlock()
+
+	check, ok := agent.checks[id]
+	if !ok {
+		return ""
+	}
+
+	return check.Status
+}
+
+func newTestAdapter(t *testing.T, agent *fakeAgent, opts ...ConsulOption) *adapter {
+	opts = append([]ConsulOption{WithReconcileInterval(0), WithLogger(logger.Nop())}, opts...)
+
+	ca, err := New(agent.URL, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return ca
+}
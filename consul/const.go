@@ -1,14 +1,18 @@
 package consul
 
 import (
-	"os"
-	"strings"
 	"time"
+
+	"github.com/leon-gopher/discovery/registry"
 )
 
+// AdapterName is name of the adapter reported by registry.Error.
+const AdapterName = "consul"
+
 const (
 	DefaultServiceWeight                  = 100
 	DefaultServiceCheckInterval           = "5s"
+	DefaultServiceCheckTTL                = "15s"
 	DefaultServiceDeregisterCriticalAfter = "24h"
 	DefaultDegradeThreshold               = 0.8
 	DefaultWatchWaitTime                  = 3 * time.Minute
@@ -16,6 +20,11 @@ const (
 	DefaultWatchRollingWindowSize         = 10
 	DefaultCalmInterval                   = 1 * time.Hour
 	DefaultRetryTimes                     = 3
+	DefaultRetryInterval                  = 1 * time.Second
+	DefaultQueryTimeout                   = 10 * time.Second
+	DefaultReconcileInterval              = 30 * time.Second
+	DefaultReconcileTimeout               = 10 * time.Second
+	DefaultDumpLogInterval                = 1 * time.Minute
 )
 
 // consul 降级策略
@@ -30,15 +39,13 @@ var DefaultServiceMeta map[string]string
 
 func init() {
 	meta := map[string]string{
-		"cloud":     "aliyun",
-		"container": "vm",
-		"registry":  "consul",
+		registry.MetaCloud:     registry.LocalCloud(),
+		registry.MetaContainer: "vm",
+		registry.MetaRegistry:  "consul",
 	}
 
-	hostname, err := os.Hostname()
-	if err == nil && len(hostname) > 0 {
-		fields := strings.Split(hostname, "-")
-		meta["zone"] = fields[0]
+	if zone := registry.LocalZone(); len(zone) > 0 {
+		meta[registry.MetaZone] = zone
 	}
 
 	DefaultServiceMeta = meta
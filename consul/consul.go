@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net/url"
 	"strconv"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -12,7 +13,10 @@ import (
 	"github.com/hashicorp/consul/api"
 	"github.com/leon-gopher/discovery/errors"
 	"github.com/leon-gopher/discovery/logger"
+	"github.com/leon-gopher/discovery/metrics"
 	"github.com/leon-gopher/discovery/registry"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
 	"golang.org/x/sync/singleflight"
 )
 
@@ -20,10 +24,14 @@ type adapter struct {
 	client       *api.Client
 	serviceList  *registry.ServiceList
 	singleflight *singleflight.Group
+	// service key => time.Time of services updated
+	updated sync.Map
 
 	watchChans chan *watchChan
 	actorChans chan *actorChan
-	stopChan   chan bool
+	stopChan   chan struct{}
+	stopOnce   sync.Once
+	loopDone   chan struct{}
 
 	uri     *url.URL
 	opts    *option
@@ -31,6 +39,14 @@ type adapter struct {
 	watches sync.Map
 	watcher registry.Watcher
 
+	// service id => check ids of TTL checks
+	ttlChecks sync.Map
+	// service id => registration, for anti-entropy. It's changed with regMux held, so reconcile never re-registers
+	// a registration deregistered concurrently.
+	registrations sync.Map
+	regMux        sync.Mutex
+	reconcileDone chan struct{}
+
 	status int32
 }
 
@@ -48,6 +64,13 @@ func New(addr string, opts ...ConsulOption) (*adapter, error) {
 		watchWaitTime:     DefaultWatchWaitTime,
 		watchDumpInterval: DefaultWatchDumpInterval,
 		calmInterval:      DefaultCalmInterval,
+		reconcileInterval: DefaultReconcileInterval,
+		queryTimeout:      DefaultQueryTimeout,
+		metrics:           metrics.Noop{},
+		tracerProvider:    otel.GetTracerProvider(),
+		logger:            logger.Global(),
+
+		deregisterCriticalAfter: DefaultServiceDeregisterCriticalAfter,
 	}
 	for _, opt := range opts {
 		opt(o)
@@ -63,26 +86,34 @@ func New(addr string, opts ...ConsulOption) (*adapter, error) {
 	}
 
 	consul := &adapter{
-		client:       client,
-		serviceList:  registry.NewServiceList(),
-		actorChans:   make(chan *actorChan, 10),
-		watchChans:   make(chan *watchChan, 10),
-		stopChan:     make(chan bool),
-		singleflight: &singleflight.Group{},
-		uri:          uri,
-		opts:         o,
+		client:      client,
+		serviceList: registry.NewServiceList(),
+		actorChans:  make(chan *actorChan, 10),
+		watchChans:  make(chan *watchChan, 10),
+		stopChan:    make(chan struct{}),
+		loopDone:    make(chan struct{}),
+
+		reconcileDone: make(chan struct{}),
+		singleflight:  &singleflight.Group{},
+		uri:           uri,
+		opts:          o,
 	}
 	if o.dumper != nil {
-		consul.dump = newDump(o.watchDumpInterval, o.dumper)
+		consul.dump = newDump(o.watchDumpInterval, o.dumper, o.metrics, o.hooks, o.logger)
 
 		go consul.dump.loop()
 	}
 
 	go consul.loop()
+	go consul.reconcileLoop()
 
 	return consul, nil
 }
 
+func (ca *adapter) Name() string {
+	return AdapterName
+}
+
 func (ca *adapter) Notify(event registry.Event) {
 	status := int32(0)
 	switch event {
@@ -106,6 +137,11 @@ func (ca *adapter) Notify(event registry.Event) {
 }
 
 func (ca *adapter) Register(srv *registry.Service, opts ...registry.RegistratorOption) error {
+	return ca.RegisterContext(context.Background(), srv, opts...)
+}
+
+// RegisterContext registers the service, the request and its retries are bounded by ctx.
+func (ca *adapter) RegisterContext(ctx context.Context, srv *registry.Service, opts ...registry.RegistratorOption) error {
 	o := new(registry.CommonRegistratorOption)
 	for _, opt := range opts {
 		switch opt := opt.(type) {
@@ -133,100 +169,270 @@ func (ca *adapter) Register(srv *registry.Service, opts ...registry.RegistratorO
 	}
 
 	//metadata contains weight
-	if _, ok := srv.Meta["weight"]; !ok {
-		if srv.Weight <= 0 {
-			srv.Weight = DefaultServiceWeight
-		}
-
-		srv.Meta["weight"] = strconv.FormatInt(int64(srv.Weight), 10)
-	}
-
-	service := &api.AgentServiceRegistration{
-		ID:      srv.ServiceID(),
-		Name:    srv.Name,
-		Address: srv.ServiceIP(),
-		Port:    srv.Port,
-		Tags:    srv.Tags,
-		Meta:    srv.Meta,
-	}
+	syncWeight(srv, ca.opts.logger)
 
-	if srv.Weight > 0 {
-		service.Weights = &api.AgentWeights{
-			Passing: int(srv.Weight),
-			Warning: int(srv.Weight),
-		}
-	}
+	service := NewServiceRegistration(srv)
 
 	// build service check, default to tcp check
+	var ttlChecks []string
 	for _, check := range o.Checks {
 		ccheck, err := ca.BuildHealthCheck(srv.Name, srv.Addr(), check)
 		if err != nil {
 			return errors.Wrap(err)
 		}
 
+		// TTL check is updated by check id, readiness check is updated by registry only
+		if check.Type == registry.HealthTypeTTL && !strings.HasSuffix(check.ID, registry.ReadinessCheckSuffix) {
+			if len(ccheck.CheckID) == 0 {
+				ccheck.CheckID = "service:" + srv.ServiceID() + ":ttl"
+				if len(ttlChecks) > 0 {
+					ccheck.CheckID += ":" + strconv.Itoa(len(ttlChecks)+1)
+				}
+			}
+
+			ttlChecks = append(ttlChecks, ccheck.CheckID)
+		}
+
 		service.Checks = append(service.Checks, ccheck)
 	}
 
-	if len(service.Checks) <= 0 {
+	if len(service.Checks) <= 0 || (len(service.Checks) == 1 && strings.HasSuffix(service.Checks[0].CheckID, registry.ReadinessCheckSuffix)) {
 		//没有注入check，给个默认的check
 		service.Checks = append(service.Checks, &api.AgentServiceCheck{
 			Name:                           srv.Name,
 			TCP:                            srv.Addr(),
 			Status:                         api.HealthPassing,
 			Interval:                       DefaultServiceCheckInterval,
-			DeregisterCriticalServiceAfter: DefaultServiceDeregisterCriticalAfter,
+			DeregisterCriticalServiceAfter: ca.opts.deregisterCriticalAfter,
 		})
 
 	}
 
-	var err error
+	err := ca.serviceRegister(ctx, service)
+	if err != nil {
+		return err
+	}
+
+	if len(ttlChecks) > 0 {
+		ca.ttlChecks.Store(service.ID, ttlChecks)
+	} else {
+		ca.ttlChecks.Delete(service.ID)
+	}
+
+	ca.regMux.Lock()
+	ca.registrations.Store(service.ID, service)
+	ca.regMux.Unlock()
+
+	return nil
+}
+
+// currentChecks copies checks of the registration with current status of the agent,
+// so that re-registering never resets a passing TTL or readiness check to its initial status.
+func (ca *adapter) currentChecks(reg *api.AgentServiceRegistration) (api.AgentServiceChecks, error) {
+	if len(reg.Checks) == 0 {
+		return reg.Checks, nil
+	}
+
+	current, err := ca.client.Agent().ChecksWithFilter(fmt.Sprintf("ServiceID == %q", reg.ID))
+	if err != nil {
+		return nil, errors.Wrap(fmt.Errorf("consul.Agent().ChecksWithFilter(%s): %v", reg.ID, err))
+	}
+
+	checks := make(api.AgentServiceChecks, 0, len(reg.Checks))
+	for _, check := range reg.Checks {
+		c := *check
+		if agentCheck, ok := current[c.CheckID]; ok && len(c.CheckID) > 0 {
+			c.Status = agentCheck.Status
+		}
+		checks = append(checks, &c)
+	}
+
+	return checks, nil
+}
+
+// UpdateCheck reports status of the TTL check registered with the service.
+func (ca *adapter) UpdateCheck(srv *registry.Service, checkID string, status registry.HealthStatus, note string) error {
+	err := ca.client.Agent().UpdateTTL(checkID, note, string(status))
+	if err != nil {
+		return errors.Wrap(fmt.Errorf("consul.Agent().UpdateTTL(%s, %s): %v", checkID, status, err))
+	}
+
+	return nil
+}
+
+// Update re-registers the service with the same id and checks, it keeps weight and Meta["weight"] in sync.
+func (ca *adapter) Update(srv *registry.Service, opts ...registry.RegistratorOption) error {
+	value, ok := ca.registrations.Load(srv.ServiceID())
+	if !ok {
+		return errors.Wrap(errors.ErrNotFound)
+	}
+	prev := value.(*api.AgentServiceRegistration)
+
+	if srv.Meta == nil {
+		srv.Meta = make(map[string]string)
+	}
+
+	// weight of meta changed only, it takes precedence
+	if prev.Weights != nil && int(srv.Weight) == prev.Weights.Passing && srv.Meta[registry.MetaWeight] != prev.Meta[registry.MetaWeight] {
+		srv.Weight = 0
+	}
+	syncWeight(srv, ca.opts.logger)
+
+	service := NewServiceRegistration(srv)
+	service.ID = prev.ID
+	service.Name = prev.Name
+	service.Checks = prev.Checks
+
+	// the registration keeps initial status of checks, while the agent keeps current status
+	checks, err := ca.currentChecks(prev)
+	if err != nil {
+		return err
+	}
+	update := *service
+	update.Checks = checks
+
+	err = ca.serviceRegister(context.Background(), &update)
+	if err != nil {
+		return err
+	}
+
+	// deregistered concurrently
+	ca.regMux.Lock()
+	if _, ok := ca.registrations.Load(service.ID); ok {
+		ca.registrations.Store(service.ID, service)
+	}
+	ca.regMux.Unlock()
+
+	return nil
+}
+
+func (ca *adapter) serviceRegister(ctx context.Context, service *api.AgentServiceRegistration) (err error) {
+	ctx, span := ca.tracer().Start(ctx, "consul.Register", trace.WithAttributes(AttrServiceID.String(service.ID)))
+	defer func() {
+		endSpan(span, err)
+	}()
+
 	for i := 0; i < DefaultRetryTimes; i++ {
-		err = ca.client.Agent().ServiceRegister(service)
+		err = ca.attempt(ctx, "consul.Agent.ServiceRegister", i+1, func(ctx context.Context) error {
+			return ca.client.Agent().ServiceRegisterOpts(service, api.ServiceRegisterOpts{}.WithContext(ctx))
+		})
 		if err == nil {
+			ca.opts.hooks.Registered(&registry.RegisterEvent{
+				ServiceID: service.ID,
+				Name:      service.Name,
+				Adapter:   AdapterName,
+				Attempt:   i + 1,
+			})
 			break
 		}
-		logger.Infof("%v times consul.Register(%s): %v", i+1, service.ID, err)
+		ca.opts.logger.Info("consul.Register() failed", "service", service.ID, "attempt", i+1, "error", err)
+
+		if i+1 < DefaultRetryTimes {
+			ca.opts.hooks.RegisterRetry(&registry.RegisterEvent{
+				ServiceID: service.ID,
+				Name:      service.Name,
+				Adapter:   AdapterName,
+				Attempt:   i + 1,
+				Err:       err,
+			})
+		}
 
-		time.Sleep(1 * time.Second)
+		if serr := sleepContext(ctx, DefaultRetryInterval); serr != nil {
+			err = serr
+			break
+		}
 	}
 
 	if err != nil {
 		return errors.Wrap(err)
 	}
 
-	logger.Infof("consul.Register(%s): OK!", service.ID)
+	ca.opts.logger.Info("consul.Register() OK", "service", service.ID)
+
+	return nil
+}
+
+// UpdateTTL reports status of all TTL checks registered with the service.
+func (ca *adapter) UpdateTTL(srv *registry.Service, status registry.HealthStatus, note string) error {
+	value, ok := ca.ttlChecks.Load(srv.ServiceID())
+	if !ok {
+		return errors.Wrap(errors.ErrNotFound)
+	}
+
+	for _, checkID := range value.([]string) {
+		err := ca.client.Agent().UpdateTTL(checkID, note, string(status))
+		if err != nil {
+			return errors.Wrap(fmt.Errorf("consul.Agent().UpdateTTL(%s, %s): %v", checkID, status, err))
+		}
+	}
 
 	return nil
 }
 
 func (ca *adapter) Deregister(srv *registry.Service, opts ...registry.RegistratorOption) error {
-	var err error
+	return ca.DeregisterContext(context.Background(), srv, opts...)
+}
+
+// DeregisterContext deregisters the service, the request and its retries are bounded by ctx.
+func (ca *adapter) DeregisterContext(ctx context.Context, srv *registry.Service, opts ...registry.RegistratorOption) (err error) {
+	ctx, span := ca.tracer().Start(ctx, "consul.Deregister", trace.WithAttributes(AttrServiceID.String(srv.ServiceID())))
+	defer func() {
+		endSpan(span, err)
+	}()
+
+	// stop anti-entropy before deregistering, it waits for re-registering in flight
+	ca.regMux.Lock()
+	ca.registrations.Delete(srv.ServiceID())
+	ca.regMux.Unlock()
+
 	for i := 0; i < DefaultRetryTimes; i++ {
-		err = ca.client.Agent().ServiceDeregister(srv.ServiceID())
+		err = ca.attempt(ctx, "consul.Agent.ServiceDeregister", i+1, func(ctx context.Context) error {
+			return ca.client.Agent().ServiceDeregisterOpts(srv.ServiceID(), (&api.QueryOptions{}).WithContext(ctx))
+		})
 		if err == nil {
 			break
 		}
 
-		logger.Infof("consul.Deregister(%s): %v", srv.ServiceID(), err)
-		time.Sleep(1 * time.Second)
+		ca.opts.logger.Info("consul.Deregister() failed", "service", srv.ServiceID(), "attempt", i+1, "error", err)
+
+		if i+1 < DefaultRetryTimes {
+			ca.opts.hooks.RegisterRetry(&registry.RegisterEvent{
+				ServiceID:  srv.ServiceID(),
+				Name:       srv.Name,
+				Adapter:    AdapterName,
+				Deregister: true,
+				Attempt:    i + 1,
+				Err:        err,
+			})
+		}
+
+		if serr := sleepContext(ctx, DefaultRetryInterval); serr != nil {
+			err = serr
+			break
+		}
 	}
 	if err != nil {
-		logger.Infof("consul.Deregister(%s): %v", srv.ServiceID(), err)
+		ca.opts.logger.Info("consul.Deregister() failed", "service", srv.ServiceID(), "error", err)
 	} else {
-		logger.Infof("consul.Deregister(%s): Done!", srv.ServiceID())
+		ca.opts.logger.Info("consul.Deregister() done", "service", srv.ServiceID())
+
+		ca.ttlChecks.Delete(srv.ServiceID())
 	}
 	return err
 }
 
-//-----------------------------discovery---------------------------
+// -----------------------------discovery---------------------------
 func (ca *adapter) BuildHealthCheck(name, addr string, check *registry.HealthCheck) (*api.AgentServiceCheck, error) {
 	if check == nil {
 		return nil, nil
 	}
 
 	healthStatus := api.HealthPassing
-	if check.Status == registry.HealthCritical {
+	switch check.Status {
+	case registry.HealthCritical:
 		healthStatus = api.HealthCritical
+	case registry.HealthWarning:
+		healthStatus = api.HealthWarning
 	}
 
 	interval := check.Interval
@@ -234,20 +440,80 @@ func (ca *adapter) BuildHealthCheck(name, addr string, check *registry.HealthChe
 		interval = time.Second
 	}
 
+	deregisterAfter := ca.opts.deregisterCriticalAfter
+	if check.DeregisterCriticalServiceAfter > 0 {
+		deregisterAfter = check.DeregisterCriticalServiceAfter.String()
+	}
+
 	health := &api.AgentServiceCheck{
+		CheckID:                        check.ID,
 		Name:                           check.Name,
 		Status:                         healthStatus,
 		Interval:                       interval.String(),
-		DeregisterCriticalServiceAfter: DefaultServiceDeregisterCriticalAfter,
+		TLSSkipVerify:                  check.TLSSkipVerify,
+		DeregisterCriticalServiceAfter: deregisterAfter,
+	}
+	if check.Timeout > 0 {
+		health.Timeout = check.Timeout.String()
 	}
+
+	// default to address of service
+	uri := check.URI
+	if len(uri) == 0 || strings.HasPrefix(uri, "/") {
+		uri = addr + uri
+	}
+
 	switch check.Type {
 	case registry.HealthTypeHTTP:
-		health.HTTP = check.URI
+		health.HTTP = uri
+		if !strings.Contains(uri, "://") {
+			health.HTTP = "http://" + uri
+		}
 		health.Method = check.Method
 		health.Header = check.Header
 
 	case registry.HealthTypeTCP:
-		health.TCP = check.URI
+		health.TCP = uri
+
+	case registry.HealthTypeTTL:
+		health.Interval = ""
+		health.TTL = DefaultServiceCheckTTL
+		if check.TTL > 0 {
+			health.TTL = check.TTL.String()
+		}
+
+	case registry.HealthTypeGRPC:
+		health.GRPC = uri
+		health.GRPCUseTLS = check.UseTLS
+
+	case registry.HealthTypeH2PING:
+		health.H2PING = uri
+		health.H2PingUseTLS = check.UseTLS
+
+	case registry.HealthTypeAlias:
+		if len(check.AliasService) == 0 {
+			return nil, errors.Wrap(errors.ErrArgument)
+		}
+
+		health.Interval = ""
+		health.AliasService = check.AliasService
+		health.AliasNode = check.AliasNode
+
+	case registry.HealthTypeDocker:
+		if len(check.DockerContainerID) == 0 || len(check.Args) == 0 {
+			return nil, errors.Wrap(errors.ErrArgument)
+		}
+
+		health.DockerContainerID = check.DockerContainerID
+		health.Shell = check.Shell
+		health.Args = check.Args
+
+	case registry.HealthTypeScript:
+		if len(check.Args) == 0 {
+			return nil, errors.Wrap(errors.ErrArgument)
+		}
+
+		health.Args = check.Args
 
 	default:
 		return nil, errors.Wrap(errors.ErrArgument)
@@ -257,21 +523,48 @@ func (ca *adapter) BuildHealthCheck(name, addr string, check *registry.HealthChe
 }
 
 func (ca *adapter) GetServices(name string, opts ...registry.DiscoveryOption) ([]*registry.Service, error) {
+	return ca.GetServicesContext(context.Background(), name, opts...)
+}
+
+// GetServicesContext returns services from cache, or fetches them from consul and starts watching. Concurrent fetches
+// of the same key are shared and bounded by WithQueryTimeout, and each caller returns once its ctx done.
+func (ca *adapter) GetServicesContext(ctx context.Context, name string, opts ...registry.DiscoveryOption) (services []*registry.Service, err error) {
 	o := registry.NewCommonDiscoveryOption(opts...)
 	key := registry.NewServiceKey(name, o.Tags, o.DC)
 
-	services, err := ca.serviceList.GetServices(key)
+	ctx, span := ca.tracer().Start(ctx, "consul.GetServices", trace.WithAttributes(AttrServiceKey.String(key.ToString())))
+	defer func() {
+		span.SetAttributes(AttrInstances.Int(len(services)))
+		endSpan(span, err)
+	}()
+
+	services, err = ca.serviceList.GetServices(key)
 	if err == nil {
+		span.SetAttributes(AttrCacheHit.Bool(true))
 		return services, nil
 	}
+	span.SetAttributes(AttrCacheHit.Bool(false))
+
+	// shared by callers, never canceled by one of them, but traced within the first one
+	fetchCtx := detachContext(ctx)
+
+	resultC := ca.singleflight.DoChan(key.ToString(), func() (interface{}, error) {
+		ctx, cancel := context.WithTimeout(fetchCtx, ca.opts.queryTimeout)
+		defer cancel()
+
+		ctx, span := ca.tracer().Start(ctx, "consul.FetchServices", trace.WithAttributes(AttrServiceKey.String(key.ToString())))
 
-	entries, err, _ := ca.singleflight.Do(key.ToString(), func() (interface{}, error) {
 		var services []*registry.Service
 		var err error
+		defer func() {
+			span.SetAttributes(AttrInstances.Int(len(services)))
+			endSpan(span, err)
+		}()
+
 		if ca.opts.firstFetchUseCatalog {
-			services, err = ca.CatalogServices(name, o.DC, o.Tags)
+			services, err = ca.CatalogServices(ctx, name, o.DC, o.Tags)
 		} else {
-			services, err = ca.ServiceMultipleTags(name, o.DC, o.Tags)
+			services, err = ca.ServiceMultipleTags(ctx, name, o.DC, o.Tags)
 		}
 
 		if err != nil {
@@ -279,21 +572,33 @@ func (ca *adapter) GetServices(name string, opts ...registry.DiscoveryOption) ([
 		}
 
 		ca.serviceList.Set(key, services)
+		ca.updated.Store(key, time.Now())
 
 		//不存在,执行一个启动流程
-		ca.actorChans <- &actorChan{
+		select {
+		case ca.actorChans <- &actorChan{
 			dc:   o.DC,
 			name: name,
 			tags: o.Tags,
+		}:
+		case <-ca.stopChan:
 		}
 
 		return services, nil
 	})
 
-	if services, ok := entries.([]*registry.Service); ok {
-		return services, err
+	select {
+	case result := <-resultC:
+		span.SetAttributes(AttrShared.Bool(result.Shared))
+
+		if services, ok := result.Val.([]*registry.Service); ok {
+			return services, result.Err
+		}
+		return nil, result.Err
+
+	case <-ctx.Done():
+		return nil, newError("GetServices", key, ctx.Err())
 	}
-	return nil, err
 }
 
 func (ca *adapter) Watch(w registry.Watcher) {
@@ -301,6 +606,8 @@ func (ca *adapter) Watch(w registry.Watcher) {
 }
 
 func (ca *adapter) loop() {
+	defer close(ca.loopDone)
+
 	for {
 		select {
 		case action := <-ca.actorChans:
@@ -311,13 +618,15 @@ func (ca *adapter) loop() {
 		case <-ca.stopChan:
 			ca.watches.Range(func(_, value interface{}) bool {
 				if w, ok := value.(*Watch); ok {
-					logger.Infof("%T.Stop(): OK!", w)
+					ca.opts.logger.Info("consul.Watch.Stop() OK", "service", w.name)
 
 					w.Stop()
 				}
 
 				return true
 			})
+
+			return
 		}
 	}
 }
@@ -332,9 +641,10 @@ func (ca *adapter) addService(service *watchChan, overwrite bool) {
 		return
 	}
 
-	entries := ServicesCovert(service.entries)
+	entries := servicesCovert(service.entries, ca.opts.logger)
 
 	ca.serviceList.Set(key, entries)
+	ca.updated.Store(key, time.Now())
 	if ca.watcher != nil {
 		ca.watcher.Watch(key, entries)
 	}
@@ -344,44 +654,78 @@ func (ca *adapter) addService(service *watchChan, overwrite bool) {
 	}
 }
 
-func (ca *adapter) CatalogServices(name string, dc string, tags []string) ([]*registry.Service, error) {
+func (ca *adapter) CatalogServices(ctx context.Context, name string, dc string, tags []string) ([]*registry.Service, error) {
 	apiOpts := &api.QueryOptions{
 		Datacenter: dc,
 		AllowStale: ca.opts.stale,
 	}
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
 	apiOpts = apiOpts.WithContext(ctx)
 
 	services, _, err := ca.client.Catalog().ServiceMultipleTags(name, tags, apiOpts)
 	if err != nil {
-		return nil, errors.Wrap(fmt.Errorf("consul.Catalog().ServiceMultipleTags(%s, %v, %v, %+v): %v", name, tags, ca.opts.passingOnly, apiOpts, err))
+		err = fmt.Errorf("consul.Catalog().ServiceMultipleTags(%s, %v, %v, %+v): %w", name, tags, ca.opts.passingOnly, apiOpts, err)
+
+		return nil, newError("CatalogServices", registry.NewServiceKey(name, tags, dc), err)
 	}
 
 	services = CatalogReduceRepeate(services, ca.opts.passingOnly)
 
-	return CatalogServiceCovert(services), nil
+	return catalogServiceCovert(services, ca.opts.logger), nil
 }
 
-func (ca *adapter) ServiceMultipleTags(name string, dc string, tags []string) ([]*registry.Service, error) {
+func (ca *adapter) ServiceMultipleTags(ctx context.Context, name string, dc string, tags []string) ([]*registry.Service, error) {
 	apiOpts := &api.QueryOptions{
 		Datacenter: dc,
 		AllowStale: ca.opts.stale,
 	}
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
 	apiOpts = apiOpts.WithContext(ctx)
 
 	services, _, err := ca.client.Health().ServiceMultipleTags(name, tags, ca.opts.passingOnly, apiOpts)
 	if err != nil {
-		return nil, errors.Wrap(fmt.Errorf("consul.Health().ServiceMultipleTags(%s, %v, %v, %+v): %v", name, tags, ca.opts.passingOnly, apiOpts, err))
+		err = fmt.Errorf("consul.Health().ServiceMultipleTags(%s, %v, %v, %+v): %w", name, tags, ca.opts.passingOnly, apiOpts, err)
+
+		return nil, newError("ServiceMultipleTags", registry.NewServiceKey(name, tags, dc), err)
 	}
 	services = ReduceRepeate(services)
-	return ServicesCovert(services), nil
+	return servicesCovert(services, ca.opts.logger), nil
 }
 
+// Stop stops all watches and loops of the adapter without waiting, see Close.
 func (ca *adapter) Stop() {
-	ca.stopChan <- true
+	ca.stopOnce.Do(func() {
+		close(ca.stopChan)
+	})
+}
+
+// Close stops all watches and loops, flushes pending dumps, and waits until all goroutines exited or ctx done.
+func (ca *adapter) Close(ctx context.Context) error {
+	ca.Stop()
+
+	for _, done := range []chan struct{}{ca.loopDone, ca.reconcileDone} {
+		select {
+		case <-done:
+		case <-ctx.Done():
+			return errors.Wrap(ctx.Err())
+		}
+	}
+
+	var err error
+	ca.watches.Range(func(_, value interface{}) bool {
+		if w, ok := value.(*Watch); ok {
+			err = w.Wait(ctx)
+		}
+
+		return err == nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if ca.dump != nil {
+		return ca.dump.Close(ctx)
+	}
+
+	return nil
 }
 
 func (ca *adapter) startWatch(name string, tags []string, dc string) {
@@ -390,13 +734,7 @@ func (ca *adapter) startWatch(name string, tags []string, dc string) {
 		return
 	}
 
-	watch := &Watch{
-		adapter:    ca,
-		dc:         dc,
-		name:       name,
-		tags:       tags,
-		watchChans: ca.watchChans,
-	}
+	watch := newWatch(ca, name, tags, dc)
 
 	//使用降级策略
 	if ca.opts.enableDegrade() {
@@ -407,3 +745,40 @@ func (ca *adapter) startWatch(name string, tags []string, dc string) {
 
 	go watch.Watch()
 }
+
+// Drain enables maintenance mode of the service, it fails TTL checks instead if maintenance mode failed.
+func (ca *adapter) Drain(ctx context.Context, srv *registry.Service, reason string) error {
+	err := ca.client.Agent().EnableServiceMaintenanceOpts(srv.ServiceID(), reason, (&api.QueryOptions{}).WithContext(ctx))
+	if err == nil {
+		ca.opts.logger.Info("consul.Drain() maintenance enabled", "service", srv.ServiceID())
+		return nil
+	}
+
+	ca.opts.logger.Error("consul.Agent().EnableServiceMaintenance() failed", "service", srv.ServiceID(), "error", err)
+
+	if _, ok := ca.ttlChecks.Load(srv.ServiceID()); ok {
+		return ca.UpdateTTL(srv, registry.HealthCritical, reason)
+	}
+
+	return errors.Wrap(err)
+}
+
+// IsDrained returns true if the service is not in passing results of consul.
+func (ca *adapter) IsDrained(ctx context.Context, srv *registry.Service) (bool, error) {
+	opts := &api.QueryOptions{
+		AllowStale: ca.opts.stale,
+	}
+
+	entries, _, err := ca.client.Health().Service(srv.Name, "", true, opts.WithContext(ctx))
+	if err != nil {
+		return false, errors.Wrap(err)
+	}
+
+	for _, entry := range entries {
+		if entry.Service != nil && entry.Service.ID == srv.ServiceID() {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
@@ -7,7 +7,7 @@ import (
 
 	"github.com/hashicorp/consul/api"
 	"github.com/leon-gopher/discovery/errors"
-	"github.com/leon-gopher/discovery/logger"
+	"github.com/leon-gopher/discovery/registry"
 )
 
 //每个降级都在 watch 的 goroutine 里执行
@@ -30,6 +30,9 @@ type passingOnlyDegrade struct {
 	threshold   float32
 	interval    time.Duration
 	passingOnly bool
+
+	//更新被保留时为 1
+	degraded int32
 }
 
 func newPassingOnlyDegrade(w *Watch) *passingOnlyDegrade {
@@ -49,8 +52,15 @@ func (p *passingOnlyDegrade) CheckStatus(entries []*api.ServiceEntry) ([]*api.Se
 
 	if p.shouldDegrade(len(entries)) {
 		p.cancelTimer()
+		p.w.option().metrics.DegradeTrip(p.w.key(), len(entries), int(atomic.LoadInt32(&p.totalNodes)))
+		if atomic.SwapInt32(&p.degraded, 1) == 0 {
+			p.w.option().hooks.Degrade(p.event(len(entries)))
+		}
 		return entries, errors.ErrDegradePass
 	}
+	if atomic.SwapInt32(&p.degraded, 0) == 1 {
+		p.w.option().hooks.Recover(p.event(len(entries)))
+	}
 
 	if p.passingOnly {
 		passingEntries := p.PassingService(entries)
@@ -86,7 +96,7 @@ func (p *passingOnlyDegrade) calcTotalNodes(total int) {
 
 	if totalNodes <= 0 {
 		atomic.StoreInt32(&p.totalNodes, int32(total))
-		logger.Infof("change total nodes form %v to %v", p.totalNodes, total)
+		p.w.option().logger.Info("consul.Degrade total nodes changed", "service", p.w.name, "total", total)
 		return
 	}
 
@@ -99,7 +109,7 @@ func (p *passingOnlyDegrade) calcTotalNodes(total int) {
 	//如果是加机器，立即更新
 	if int32(total) > totalNodes {
 		atomic.StoreInt32(&p.totalNodes, int32(total))
-		logger.Infof("change total nodes form %v to %v", p.totalNodes, total)
+		p.w.option().logger.Info("consul.Degrade total nodes changed", "service", p.w.name, "total", total)
 		p.cancelTimer()
 		return
 	}
@@ -109,7 +119,7 @@ func (p *passingOnlyDegrade) calcTotalNodes(total int) {
 		p.totalNodesTimer.Reset(p.interval)
 	} else {
 		p.totalNodesTimer = time.AfterFunc(p.interval, func() {
-			logger.Infof("change totalnodes form %v to %v", p.totalNodes, p.nextTotalNodes)
+			p.w.option().logger.Info("consul.Degrade total nodes changed", "service", p.w.name, "total", p.nextTotalNodes)
 			atomic.StoreInt32(&p.totalNodes, p.nextTotalNodes)
 		})
 	}
@@ -121,3 +131,19 @@ func (p *passingOnlyDegrade) cancelTimer() {
 		p.totalNodesTimer.Stop()
 	}
 }
+
+func (p *passingOnlyDegrade) event(current int) *registry.DegradeEvent {
+	return &registry.DegradeEvent{
+		Key:       p.w.key(),
+		Adapter:   AdapterName,
+		Source:    registry.DegradePassingOnly,
+		Current:   current,
+		Total:     int(atomic.LoadInt32(&p.totalNodes)),
+		Threshold: p.threshold,
+	}
+}
+
+// IsDegraded reports whether updates are held back currently, and total nodes used for threshold.
+func (p *passingOnlyDegrade) IsDegraded() (bool, int) {
+	return atomic.LoadInt32(&p.degraded) == 1, int(atomic.LoadInt32(&p.totalNodes))
+}
@@ -1,12 +1,16 @@
 package consul
 
 import (
+	"context"
+	"fmt"
+	"sync"
 	"time"
 
 	"github.com/leon-gopher/discovery/dumper"
 
 	"github.com/leon-gopher/discovery/errors"
 	"github.com/leon-gopher/discovery/logger"
+	"github.com/leon-gopher/discovery/metrics"
 	"github.com/leon-gopher/discovery/registry"
 )
 
@@ -16,16 +20,36 @@ type Dump struct {
 	disable  bool
 	disableC chan bool
 	interval time.Duration
-	last     map[registry.ServiceKey]time.Time
+	metrics  metrics.Metrics
+	hooks    *registry.Hooks
+	log      logger.Structured
+	// logs of every store are limited by DefaultDumpLogInterval
+	verbose logger.Structured
+	last    map[registry.ServiceKey]time.Time
+	// latest services skipped by interval, they are flushed when closing
+	pending map[registry.ServiceKey][]*registry.Service
+
+	stopC    chan struct{}
+	stopOnce sync.Once
+	doneC    chan struct{}
 }
 
-func newDump(interval time.Duration, dumper dumper.Dumper) *Dump {
+func newDump(interval time.Duration, dumper dumper.Dumper, m metrics.Metrics, hooks *registry.Hooks, log logger.Structured) *Dump {
+	log = log.With("dumper", fmt.Sprintf("%T", dumper))
+
 	return &Dump{
 		dumper:   dumper,
+		metrics:  m,
+		hooks:    hooks,
+		log:      log,
+		verbose:  logger.RateLimit(log, DefaultDumpLogInterval),
 		dumpC:    make(chan *dumpService, 1),
 		disableC: make(chan bool, 1),
 		last:     make(map[registry.ServiceKey]time.Time),
+		pending:  make(map[registry.ServiceKey][]*registry.Service),
 		interval: interval,
+		stopC:    make(chan struct{}),
+		doneC:    make(chan struct{}),
 	}
 }
 
@@ -35,13 +59,18 @@ type dumpService struct {
 }
 
 func (d *Dump) dump(key registry.ServiceKey, services []*registry.Service) {
-	d.dumpC <- &dumpService{
+	select {
+	case d.dumpC <- &dumpService{
 		key:      key,
 		services: services,
+	}:
+	case <-d.stopC:
 	}
 }
 
 func (d *Dump) loop() {
+	defer close(d.doneC)
+
 	for {
 		select {
 		case job := <-d.dumpC:
@@ -50,20 +79,21 @@ func (d *Dump) loop() {
 			}
 
 			if d.last[job.key].Add(d.interval).After(time.Now()) {
+				d.pending[job.key] = job.services
 				continue
 			}
 
-			logger.Infof("%T.Store(%v): services: %v, last: %v, next: %v", d.dumper, job.key, len(job.services), d.last[job.key], d.last[job.key].Add(d.interval))
+			d.verbose.Info("consul.Dump.Store()", "service", job.key.ToString(), "services", len(job.services), "last", d.last[job.key], "next", d.last[job.key].Add(d.interval))
 
 			lastModify, err := d.dumper.LastModify(job.key)
 			if err != nil {
 				switch {
 				case !errors.Is(err, errors.ErrNotFound):
-					logger.Errorf("%T.LastModify(%s): %v", d.dumper, job.key, err)
+					d.log.Error("consul.Dump.LastModify() failed", "service", job.key.ToString(), "error", err)
 					continue
 
 				default:
-					logger.Infof("%T.LastModify(%s): %v", d.dumper, job.key, err)
+					d.verbose.Info("consul.Dump.LastModify() not found", "service", job.key.ToString())
 
 					err = nil
 				}
@@ -71,30 +101,88 @@ func (d *Dump) loop() {
 
 			if lastModify.Add(d.interval).After(time.Now()) {
 				d.last[job.key] = lastModify
+				d.pending[job.key] = job.services
 				continue
 			}
 
-			err = d.dumper.Store(job.key, job.services)
-			if err != nil {
-				logger.Errorf("%T.Store(%s): services: %d, error: %v", d.dumper, job.key, len(job.services), err)
-			} else {
-				logger.Infof("%T.Store(%s): services: %v, OK!", d.dumper, job.key, len(job.services))
-
-				d.last[job.key] = time.Now()
-			}
+			d.store(job.key, job.services)
 
 		case disable := <-d.disableC:
 			if disable {
-				logger.Infof("dump.%T(): Enabled!", d.dumper)
+				d.log.Info("consul.Dump disabled")
 			} else {
-				logger.Infof("dump.%T(): Disabled!", d.dumper)
+				d.log.Info("consul.Dump enabled")
 			}
 
 			d.disable = disable
+
+		case <-d.stopC:
+			d.flush()
+			return
+		}
+	}
+}
+
+func (d *Dump) store(key registry.ServiceKey, services []*registry.Service) {
+	err := d.dumper.Store(key, services)
+	d.metrics.DumpStore(key, err)
+	if err != nil {
+		d.hooks.DumpFailure(&registry.DumpEvent{
+			Key:       key,
+			Instances: len(services),
+			Err:       err,
+		})
+
+		d.log.Error("consul.Dump.Store() failed", "service", key.ToString(), "services", len(services), "error", err)
+		return
+	}
+
+	d.verbose.Info("consul.Dump.Store() OK", "service", key.ToString(), "services", len(services))
+
+	d.last[key] = time.Now()
+	delete(d.pending, key)
+}
+
+// flush stores queued and pending services regardless of interval.
+func (d *Dump) flush() {
+	for drained := false; !drained; {
+		select {
+		case job := <-d.dumpC:
+			if !d.disable {
+				d.pending[job.key] = job.services
+			}
+
+		default:
+			drained = true
 		}
 	}
+
+	if d.disable {
+		return
+	}
+
+	for key, services := range d.pending {
+		d.store(key, services)
+	}
 }
 
 func (d *Dump) SetDisable(disable bool) {
-	d.disableC <- disable
+	select {
+	case d.disableC <- disable:
+	case <-d.stopC:
+	}
+}
+
+// Close flushes pending services and waits until the loop exited or ctx done.
+func (d *Dump) Close(ctx context.Context) error {
+	d.stopOnce.Do(func() {
+		close(d.stopC)
+	})
+
+	select {
+	case <-d.doneC:
+		return nil
+	case <-ctx.Done():
+		return errors.Wrap(ctx.Err())
+	}
 }
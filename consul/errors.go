@@ -0,0 +1,37 @@
+package consul
+
+import (
+	"net"
+
+	"github.com/hashicorp/consul/api"
+	"github.com/leon-gopher/discovery/errors"
+	"github.com/leon-gopher/discovery/registry"
+)
+
+// newError classifies err returned by consul api with its HTTP status or network failure, see errors.KindOf.
+func newError(op string, key registry.ServiceKey, err error) error {
+	e := registry.NewError(op, AdapterName, key, err)
+
+	var statusErr api.StatusError
+	var netErr net.Error
+	switch {
+	case errors.As(err, &statusErr):
+		e.StatusCode = statusErr.Code
+		e.Kind = errors.KindOfStatus(statusErr.Code)
+
+	case e.Kind == errors.KindCanceled, e.Kind == errors.KindTimeout:
+		// canceled or deadline of ctx, classified already
+
+	case errors.As(err, &netErr):
+		e.Kind = errors.KindUnavailable
+		if netErr.Timeout() {
+			e.Kind = errors.KindTimeout
+		}
+
+	case e.Kind == errors.KindUnknown:
+		// consul api reports unreachable agent without typed error
+		e.Kind = errors.KindUnavailable
+	}
+
+	return errors.Wrap(e)
+}
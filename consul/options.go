@@ -4,6 +4,10 @@ import (
 	"time"
 
 	"github.com/leon-gopher/discovery/dumper"
+	"github.com/leon-gopher/discovery/logger"
+	"github.com/leon-gopher/discovery/metrics"
+	"github.com/leon-gopher/discovery/registry"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type option struct {
@@ -23,6 +27,19 @@ type option struct {
 	firstFetchUseCatalog bool
 
 	calmInterval time.Duration
+
+	// timeout of fetching services without watch
+	queryTimeout time.Duration
+
+	metrics        metrics.Metrics
+	tracerProvider trace.TracerProvider
+	hooks          *registry.Hooks
+	logger         logger.Structured
+
+	deregisterCriticalAfter string
+
+	// anti-entropy of registrations
+	reconcileInterval time.Duration
 }
 
 func (o *option) enableDegrade() bool {
@@ -101,3 +118,62 @@ func WithCalmInterval(interval time.Duration) ConsulOption {
 		o.calmInterval = interval
 	}
 }
+
+// WithDeregisterCriticalServiceAfter 设置 check 持续 critical 后注销服务的时间，默认为 24h
+func WithDeregisterCriticalServiceAfter(d time.Duration) ConsulOption {
+	return func(o *option) {
+		if d > 0 {
+			o.deregisterCriticalAfter = d.String()
+		}
+	}
+}
+
+// WithReconcileInterval 设置注册信息自检的间隔，<=0 时关闭
+func WithReconcileInterval(interval time.Duration) ConsulOption {
+	return func(o *option) {
+		o.reconcileInterval = interval
+	}
+}
+
+// WithQueryTimeout 设置首次拉取服务的超时时间，默认为 10s
+func WithQueryTimeout(timeout time.Duration) ConsulOption {
+	return func(o *option) {
+		if timeout > 0 {
+			o.queryTimeout = timeout
+		}
+	}
+}
+
+// WithMetrics 上报 watch、降级及 dump 的指标
+func WithMetrics(m metrics.Metrics) ConsulOption {
+	return func(o *option) {
+		if m != nil {
+			o.metrics = m
+		}
+	}
+}
+
+// WithTracerProvider 使用 tp 创建首次拉取及注册的 span，默认为 otel 全局的 TracerProvider
+func WithTracerProvider(tp trace.TracerProvider) ConsulOption {
+	return func(o *option) {
+		if tp != nil {
+			o.tracerProvider = tp
+		}
+	}
+}
+
+// WithHooks 回调降级、dump 失败、注册重试及 watch 错误等事件
+func WithHooks(hooks *registry.Hooks) ConsulOption {
+	return func(o *option) {
+		o.hooks = hooks
+	}
+}
+
+// WithLogger 使用 l 输出日志，默认为全局 logger
+func WithLogger(l logger.Structured) ConsulOption {
+	return func(o *option) {
+		if l != nil {
+			o.logger = l
+		}
+	}
+}
@@ -0,0 +1,181 @@
+package consul
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/consul/api"
+	"github.com/leon-gopher/discovery/registry"
+)
+
+// MaintenanceCheckPrefix is check id prefix of service in maintenance mode.
+const MaintenanceCheckPrefix = "_service_maintenance:"
+
+// reasons of repairs
+const (
+	RepairMissing = "missing"
+	RepairDrifted = "drifted"
+)
+
+// Repair describes a registration repaired by anti-entropy.
+type Repair = registry.RepairEvent
+
+// reconcileLoop compares registrations recorded with services of the local agent, and re-registers anything
+// missing or drifted. It happens when the agent restarted or its data dir wiped.
+func (ca *adapter) reconcileLoop() {
+	defer close(ca.reconcileDone)
+
+	if ca.opts.reconcileInterval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(ca.opts.reconcileInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			ca.reconcile()
+
+		case <-ca.stopChan:
+			return
+		}
+	}
+}
+
+func (ca *adapter) reconcile() {
+	var registrations []*api.AgentServiceRegistration
+	ca.registrations.Range(func(_, value interface{}) bool {
+		registrations = append(registrations, value.(*api.AgentServiceRegistration))
+		return true
+	})
+	if len(registrations) == 0 {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultReconcileTimeout)
+	defer cancel()
+
+	services, err := ca.client.Agent().ServicesWithFilterOpts("", (&api.QueryOptions{}).WithContext(ctx))
+	if err != nil {
+		ca.opts.logger.Error("consul.Agent().Services() failed", "error", err)
+		return
+	}
+
+	checks, err := ca.client.Agent().ChecksWithFilterOpts("", (&api.QueryOptions{}).WithContext(ctx))
+	if err != nil {
+		ca.opts.logger.Error("consul.Agent().Checks() failed", "error", err)
+		return
+	}
+
+	serviceChecks := make(map[string]int)
+	for id, check := range checks {
+		// checks added by maintenance mode
+		if strings.HasPrefix(id, MaintenanceCheckPrefix) {
+			continue
+		}
+
+		serviceChecks[check.ServiceID]++
+	}
+
+	for _, reg := range registrations {
+		repair := &Repair{
+			ServiceID: reg.ID,
+			Name:      reg.Name,
+			Adapter:   AdapterName,
+		}
+
+		service, ok := services[reg.ID]
+		if ok {
+			repair.Detail = diffRegistration(reg, service, serviceChecks[reg.ID])
+			if len(repair.Detail) == 0 {
+				continue
+			}
+
+			repair.Reason = RepairDrifted
+		} else {
+			repair.Reason = RepairMissing
+		}
+
+		ok, repair.Err = ca.reregister(ctx, reg)
+		if !ok {
+			continue
+		}
+
+		if repair.Err != nil {
+			ca.opts.logger.Error("consul.Reconcile() re-register failed", "service", reg.ID, "reason", repair.Reason, "detail", repair.Detail, "error", repair.Err)
+		} else {
+			ca.opts.logger.Warn("consul.Reconcile() re-registered", "service", reg.ID, "reason", repair.Reason, "detail", repair.Detail)
+		}
+
+		ca.opts.hooks.Repair(repair)
+	}
+}
+
+// reregister registers reg again, it returns false if reg is deregistered or updated since. It holds regMux, so a
+// concurrent deregistering waits and is never undone.
+func (ca *adapter) reregister(ctx context.Context, reg *api.AgentServiceRegistration) (bool, error) {
+	ca.regMux.Lock()
+	defer ca.regMux.Unlock()
+
+	value, ok := ca.registrations.Load(reg.ID)
+	if !ok || value.(*api.AgentServiceRegistration) != reg {
+		return false, nil
+	}
+
+	err := ca.client.Agent().ServiceRegisterOpts(reg, api.ServiceRegisterOpts{}.WithContext(ctx))
+
+	return true, err
+}
+
+// diffRegistration returns which part of the service drifted from registration, empty if nothing.
+func diffRegistration(reg *api.AgentServiceRegistration, service *api.AgentService, checks int) string {
+	switch {
+	case reg.Address != service.Address || reg.Port != service.Port:
+		return fmt.Sprintf("address: %s:%d => %s:%d", reg.Address, reg.Port, service.Address, service.Port)
+
+	case !equalStrings(reg.Tags, service.Tags):
+		return fmt.Sprintf("tags: %v => %v", reg.Tags, service.Tags)
+
+	case !equalMeta(reg.Meta, service.Meta):
+		return fmt.Sprintf("meta: %v => %v", reg.Meta, service.Meta)
+
+	case reg.Weights != nil && *reg.Weights != service.Weights:
+		return fmt.Sprintf("weights: %+v => %+v", *reg.Weights, service.Weights)
+
+	case len(reg.Checks) != checks:
+		return fmt.Sprintf("checks: %d => %d", len(reg.Checks), checks)
+	}
+
+	return ""
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+func equalMeta(a, b map[string]string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for k, v := range a {
+		if value, ok := b[k]; !ok || value != v {
+			return false
+		}
+	}
+
+	return true
+}
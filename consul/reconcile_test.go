@@ -0,0 +1,190 @@
+package consul
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/consul/api"
+	"github.com/leon-gopher/discovery/registry"
+)
+
+func TestReconcile(t *testing.T) {
+	cases := []struct {
+		name string
+		// drift changes the agent after registering, it's called with agent.mux held
+		drift  func(agent *fakeAgent)
+		reason string
+	}{
+		{
+			name: "in sync",
+		},
+		{
+			name: "missing",
+			drift: func(agent *fakeAgent) {
+				delete(agent.services, "svc-1")
+			},
+			reason: RepairMissing,
+		},
+		{
+			name: "tags drifted",
+			drift: func(agent *fakeAgent) {
+				agent.services["svc-1"].Tags = []string{"stale"}
+			},
+			reason: RepairDrifted,
+		},
+		{
+			name: "check lost",
+			drift: func(agent *fakeAgent) {
+				for id := range agent.checks {
+					delete(agent.checks, id)
+				}
+			},
+			reason: RepairDrifted,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			agent := newFakeAgent(t)
+			defer agent.Close()
+
+			var repairs []*Repair
+			ca := newTestAdapter(t, agent, WithHooks(&registry.Hooks{
+				OnRepair: func(repair *Repair) {
+					repairs = append(repairs, repair)
+				},
+			}))
+			defer ca.Close(context.Background())
+
+			srv := &registry.Service{ID: "svc-1", Name: "svc", IP: "10.0.0.1", Port: 80, Tags: []string{"v1"}}
+			if err := ca.Register(srv); err != nil {
+				t.Fatalf("Register(): %v", err)
+			}
+
+			if tc.drift != nil {
+				agent.mux.Lock()
+				tc.drift(agent)
+				agent.mux.Unlock()
+			}
+
+			ca.reconcile()
+
+			if len(tc.reason) == 0 {
+				if len(repairs) != 0 {
+					t.Fatalf("unexpected repairs: %+v", repairs[0])
+				}
+				return
+			}
+
+			if len(repairs) != 1 || repairs[0].Reason != tc.reason || repairs[0].Err != nil {
+				t.Fatalf("repairs: %+v, expected one of %s", repairs, tc.reason)
+			}
+
+			service := agent.service("svc-1")
+			if service == nil || len(service.Tags) != 1 || service.Tags[0] != "v1" {
+				t.Fatalf("service not repaired: %+v", service)
+			}
+
+			// repaired
+			ca.reconcile()
+			if len(repairs) != 1 {
+				t.Fatalf("repaired again: %+v", repairs[1])
+			}
+		})
+	}
+}
+
+func TestReconcileKeepsRegistrationOfCaller(t *testing.T) {
+	agent := newFakeAgent(t)
+	defer agent.Close()
+
+	ca := newTestAdapter(t, agent)
+	defer ca.Close(context.Background())
+
+	srv := &registry.Service{ID: "svc-1", Name: "svc", IP: "10.0.0.1", Port: 80, Tags: []string{"v1"}}
+	if err := ca.Register(srv); err != nil {
+		t.Fatalf("Register(): %v", err)
+	}
+
+	// changes of the caller are not registered without Update
+	srv.Tags[0] = "v2"
+	srv.Meta["owner"] = "someone"
+
+	ca.reconcile()
+
+	agent.mux.Lock()
+	registered := len(agent.registered)
+	agent.mux.Unlock()
+
+	if registered != 1 {
+		t.Fatalf("registered %d times, expected once", registered)
+	}
+}
+
+func TestReconcileNeverUndoesDeregister(t *testing.T) {
+	agent := newFakeAgent(t)
+	defer agent.Close()
+
+	ca := newTestAdapter(t, agent)
+	defer ca.Close(context.Background())
+
+	srv := &registry.Service{ID: "svc-1", Name: "svc", IP: "10.0.0.1", Port: 80}
+	if err := ca.Register(srv); err != nil {
+		t.Fatalf("Register(): %v", err)
+	}
+
+	// lost by the agent, and deregistered while it's being re-registered
+	agent.deregister("svc-1")
+
+	entered := make(chan struct{})
+	release := make(chan struct{})
+	agent.mux.Lock()
+	agent.onRegister = func(*api.AgentServiceRegistration) {
+		close(entered)
+		<-release
+	}
+	agent.mux.Unlock()
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+
+		ca.reconcile()
+	}()
+
+	<-entered
+
+	deregistered := make(chan error, 1)
+	go func() {
+		deregistered <- ca.Deregister(srv)
+	}()
+
+	select {
+	case err := <-deregistered:
+		t.Fatalf("Deregister() returned during re-registering: %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(release)
+	wg.Wait()
+
+	if err := <-deregistered; err != nil {
+		t.Fatalf("Deregister(): %v", err)
+	}
+	if service := agent.service("svc-1"); service != nil {
+		t.Fatalf("deregistered service is registered again: %+v", service)
+	}
+
+	// never re-registered once deregistered
+	agent.mux.Lock()
+	agent.onRegister = nil
+	agent.mux.Unlock()
+
+	ca.reconcile()
+	if service := agent.service("svc-1"); service != nil {
+		t.Fatalf("deregistered service is registered again: %+v", service)
+	}
+}
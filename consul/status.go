@@ -0,0 +1,84 @@
+package consul
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/hashicorp/consul/api"
+	"github.com/leon-gopher/discovery/errors"
+	"github.com/leon-gopher/discovery/registry"
+)
+
+// Status reports services cached by the adapter, with index and degrade state of their watches.
+func (ca *adapter) Status() []*registry.ServiceStatus {
+	var statuses []*registry.ServiceStatus
+
+	ca.serviceList.Range(func(key registry.ServiceKey, services []*registry.Service) bool {
+		status := &registry.ServiceStatus{
+			Key:       key,
+			Adapter:   AdapterName,
+			Source:    registry.SourceConsulOneShot,
+			Instances: len(services),
+			Services:  services,
+			Threshold: ca.opts.threshold,
+		}
+
+		if updated, ok := ca.updated.Load(key); ok {
+			status.UpdatedAt = updated.(time.Time)
+		}
+
+		if value, ok := ca.watches.Load(key); ok {
+			w := value.(*Watch)
+
+			status.Source = registry.SourceConsulWatch
+			status.LastIndex = w.LastIndex()
+
+			for _, degrade := range w.degrades {
+				if p, ok := degrade.(*passingOnlyDegrade); ok {
+					status.Degraded, status.TotalNodes = p.IsDegraded()
+				}
+			}
+		}
+
+		if namer, ok := ca.opts.dumper.(filenamer); ok {
+			status.DumpFile = namer.Filename(key)
+		}
+		if ca.opts.dumper != nil {
+			if modified, err := ca.opts.dumper.LastModify(key); err == nil {
+				status.DumpModifiedAt = modified
+			}
+		}
+
+		statuses = append(statuses, status)
+		return true
+	})
+
+	return statuses
+}
+
+// Checks reports status of health checks registered with the service by local agent.
+func (ca *adapter) Checks(ctx context.Context, srv *registry.Service) ([]*registry.CheckStatus, error) {
+	checks, err := ca.client.Agent().ChecksWithFilterOpts(fmt.Sprintf("ServiceID == %q", srv.ServiceID()), (&api.QueryOptions{}).WithContext(ctx))
+	if err != nil {
+		return nil, errors.Wrap(fmt.Errorf("consul.Agent().Checks(%s): %v", srv.ServiceID(), err))
+	}
+
+	statuses := make([]*registry.CheckStatus, 0, len(checks))
+	for _, check := range checks {
+		statuses = append(statuses, &registry.CheckStatus{
+			ID:     check.CheckID,
+			Name:   check.Name,
+			Type:   check.Type,
+			Status: registry.HealthStatus(check.Status),
+			Output: check.Output,
+		})
+	}
+
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].ID < statuses[j].ID
+	})
+
+	return statuses, nil
+}
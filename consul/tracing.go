@@ -0,0 +1,51 @@
+package consul
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TracerName is instrumentation name of spans created by the adapter.
+const TracerName = "github.com/leon-gopher/discovery/consul"
+
+// attributes of spans
+const (
+	AttrServiceKey   = attribute.Key("discovery.service.key")
+	AttrServiceID    = attribute.Key("discovery.service.id")
+	AttrInstances    = attribute.Key("discovery.instances")
+	AttrCacheHit     = attribute.Key("discovery.consul.cache_hit")
+	AttrShared       = attribute.Key("discovery.consul.singleflight_shared")
+	AttrRetryAttempt = attribute.Key("discovery.consul.attempt")
+)
+
+func (ca *adapter) tracer() trace.Tracer {
+	return ca.opts.tracerProvider.Tracer(TracerName)
+}
+
+// detachContext returns a context carrying span of ctx without its cancellation and deadline, it is used by requests
+// shared among callers.
+func detachContext(ctx context.Context) context.Context {
+	return trace.ContextWithSpanContext(context.Background(), trace.SpanContextFromContext(ctx))
+}
+
+// attempt calls fn within a child span of the retry attempt.
+func (ca *adapter) attempt(ctx context.Context, name string, attempt int, fn func(context.Context) error) error {
+	ctx, span := ca.tracer().Start(ctx, name, trace.WithAttributes(AttrRetryAttempt.Int(attempt)))
+
+	err := fn(ctx)
+	endSpan(span, err)
+
+	return err
+}
+
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	span.End()
+}
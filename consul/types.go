@@ -24,3 +24,8 @@ type actorServices struct {
 	services []*registry.Service
 	err      error
 }
+
+// filenamer is implemented by dumper which stores dumps in files, e.g. *file.Dumper.
+type filenamer interface {
+	Filename(registry.ServiceKey) string
+}
@@ -0,0 +1,94 @@
+package consul
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/leon-gopher/discovery/registry"
+)
+
+func TestUpdateKeepsCheckStatus(t *testing.T) {
+	agent := newFakeAgent(t)
+	defer agent.Close()
+
+	ca := newTestAdapter(t, agent)
+	defer ca.Close(context.Background())
+
+	const readiness = "service:svc-1" + registry.ReadinessCheckSuffix
+
+	srv := &registry.Service{ID: "svc-1", Name: "svc", IP: "10.0.0.1", Port: 80, Weight: 100}
+	err := ca.Register(srv, registry.WithTTLHealthCheck(&registry.TTLHealthCheck{
+		ID:     readiness,
+		Name:   "readiness",
+		TTL:    time.Minute,
+		Status: registry.HealthCritical,
+	}))
+	if err != nil {
+		t.Fatalf("Register(): %v", err)
+	}
+
+	if err := ca.UpdateCheck(srv, readiness, registry.HealthPassing, "ready"); err != nil {
+		t.Fatalf("UpdateCheck(): %v", err)
+	}
+
+	srv.Weight = 50
+	if err := ca.Update(srv); err != nil {
+		t.Fatalf("Update(): %v", err)
+	}
+
+	if status := agent.checkStatus(readiness); status != string(registry.HealthPassing) {
+		t.Fatalf("readiness check %s after Update(), expected passing", status)
+	}
+	if service := agent.service("svc-1"); service == nil || service.Weights.Passing != 50 {
+		t.Fatalf("service not updated: %+v", service)
+	}
+
+	// a check deregistered by the agent is re-registered with its initial status
+	agent.mux.Lock()
+	delete(agent.checks, readiness)
+	agent.mux.Unlock()
+
+	if err := ca.Update(srv); err != nil {
+		t.Fatalf("Update(): %v", err)
+	}
+	if status := agent.checkStatus(readiness); status != string(registry.HealthCritical) {
+		t.Fatalf("readiness check %s after Update(), expected critical", status)
+	}
+}
+
+func TestBuildHealthCheckHTTP(t *testing.T) {
+	cases := []struct {
+		name string
+		uri  string
+		http string
+	}{
+		{name: "default", http: "http://10.0.0.1:80"},
+		{name: "path", uri: "/health", http: "http://10.0.0.1:80/health"},
+		{name: "url", uri: "https://example.com/health", http: "https://example.com/health"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			agent := newFakeAgent(t)
+			defer agent.Close()
+
+			ca := newTestAdapter(t, agent)
+			defer ca.Close(context.Background())
+
+			srv := &registry.Service{ID: "svc-1", Name: "svc", IP: "10.0.0.1", Port: 80}
+			err := ca.Register(srv, registry.WithHTTPHealthCheck(&registry.HTTPHealthCheck{URI: tc.uri, Interval: time.Second}))
+			if err != nil {
+				t.Fatalf("Register(): %v", err)
+			}
+
+			agent.mux.Lock()
+			reg := agent.registered[0]
+			agent.mux.Unlock()
+
+			if len(reg.Checks) != 1 || reg.Checks[0].HTTP != tc.http {
+				t.Fatalf("checks: %+v, expected HTTP %s", reg.Checks, tc.http)
+			}
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package consul
 
 import (
+	"context"
 	"math/rand"
 	"strconv"
 	"time"
@@ -17,14 +18,18 @@ func SlidingDuration(d time.Duration) time.Duration {
 }
 
 func ServicesCovert(src []*api.ServiceEntry) []*registry.Service {
+	return servicesCovert(src, logger.Global())
+}
+
+func servicesCovert(src []*api.ServiceEntry, log logger.Structured) []*registry.Service {
 	entries := make([]*registry.Service, 0, len(src))
 
 	for _, entry := range src {
 		weight := int32(DefaultServiceWeight)
-		if weightStr, ok := entry.Service.Meta["weight"]; ok {
+		if weightStr, ok := entry.Service.Meta[registry.MetaWeight]; ok {
 			weightInt64, err := strconv.ParseInt(weightStr, 10, 64)
 			if err != nil {
-				logger.Errorf("service(%v) weight parse err: %v", entry.Service.Service, err)
+				log.Error("consul: invalid weight", "service", entry.Service.Service, "weight", weightStr, "error", err)
 			} else {
 				weight = int32(weightInt64)
 			}
@@ -47,13 +52,17 @@ func ServicesCovert(src []*api.ServiceEntry) []*registry.Service {
 }
 
 func CatalogServiceCovert(src []*api.CatalogService) []*registry.Service {
+	return catalogServiceCovert(src, logger.Global())
+}
+
+func catalogServiceCovert(src []*api.CatalogService, log logger.Structured) []*registry.Service {
 	entries := make([]*registry.Service, 0, len(src))
 	for _, entry := range src {
 		weight := int32(DefaultServiceWeight)
-		if weightStr, ok := entry.ServiceMeta["weight"]; ok {
+		if weightStr, ok := entry.ServiceMeta[registry.MetaWeight]; ok {
 			weightInt64, err := strconv.ParseInt(weightStr, 10, 64)
 			if err != nil {
-				logger.Errorf("service(%v) weight parse err:%v", entry.ServiceName, err)
+				log.Error("consul: invalid weight", "service", entry.ServiceName, "weight", weightStr, "error", err)
 			} else {
 				weight = int32(weightInt64)
 			}
@@ -112,3 +121,57 @@ func ReduceRepeate(entries []*api.ServiceEntry) []*api.ServiceEntry {
 
 	return newEntries
 }
+
+// syncWeight keeps weight and Meta["weight"] of service in sync, positive weight takes precedence over the meta.
+func syncWeight(srv *registry.Service, log logger.Structured) {
+	if srv.Meta == nil {
+		srv.Meta = make(map[string]string)
+	}
+
+	if srv.Weight <= 0 {
+		srv.Weight = DefaultServiceWeight
+
+		if weightStr, ok := srv.Meta[registry.MetaWeight]; ok {
+			weightInt64, err := strconv.ParseInt(weightStr, 10, 32)
+			if err == nil && weightInt64 > 0 {
+				srv.Weight = int32(weightInt64)
+			} else {
+				log.Error("consul: invalid weight", "service", srv.Name, "weight", weightStr)
+			}
+		}
+	}
+
+	srv.Meta[registry.MetaWeight] = strconv.FormatInt(int64(srv.Weight), 10)
+}
+
+// NewServiceRegistration converts service to consul registration without checks.
+func NewServiceRegistration(srv *registry.Service) *api.AgentServiceRegistration {
+	// copy tags and meta, registrations are kept for anti-entropy and must not change with srv
+	clone := srv.Clone()
+
+	return &api.AgentServiceRegistration{
+		ID:      srv.ServiceID(),
+		Name:    srv.Name,
+		Address: srv.ServiceIP(),
+		Port:    srv.Port,
+		Tags:    clone.Tags,
+		Meta:    clone.Meta,
+		Weights: &api.AgentWeights{
+			Passing: int(srv.Weight),
+			Warning: int(srv.Weight),
+		},
+	}
+}
+
+// sleepContext sleeps d, it returns ctx.Err() if ctx done before.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -2,14 +2,17 @@ package consul
 
 import (
 	"context"
+	"fmt"
 	"log"
 	"os"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/hashicorp/consul/api"
 	"github.com/hashicorp/consul/api/watch"
 	"github.com/leon-gopher/discovery/errors"
-	"github.com/leon-gopher/discovery/logger"
+	"github.com/leon-gopher/discovery/registry"
 )
 
 type Watch struct {
@@ -26,6 +29,27 @@ type Watch struct {
 	lastIndex uint64
 	rolling   *rollingWindow
 	delay     time.Duration
+
+	// lifecycle
+	mux    sync.Mutex
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func newWatch(ca *adapter, name string, tags []string, dc string) *Watch {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &Watch{
+		adapter:    ca,
+		dc:         dc,
+		name:       name,
+		tags:       tags,
+		watchChans: ca.watchChans,
+		ctx:        ctx,
+		cancel:     cancel,
+		done:       make(chan struct{}),
+	}
 }
 
 func (w *Watch) option() *option {
@@ -36,11 +60,17 @@ func (w *Watch) isDebug() bool {
 	return w.option().debug
 }
 
+func (w *Watch) key() registry.ServiceKey {
+	return registry.NewServiceKey(w.name, w.tags, w.dc)
+}
+
 func (w *Watch) consul() *api.Client {
 	return w.adapter.client
 }
 
 func (w *Watch) Watch() error {
+	defer close(w.done)
+
 	plan, err := watch.Parse(map[string]interface{}{
 		"type":    "service",
 		"service": w.name,
@@ -55,12 +85,19 @@ func (w *Watch) Watch() error {
 	plan.Handler = w.Handler
 	plan.Watcher = w.ServiceWatch()
 
-	w.plan = plan
 	w.rolling = NewRollingWindow(DefaultWatchRollingWindowSize)
 
+	w.mux.Lock()
+	if w.ctx.Err() != nil {
+		w.mux.Unlock()
+		return nil
+	}
+	w.plan = plan
+	w.mux.Unlock()
+
 	err = plan.RunWithClientAndLogger(w.consul(), log.New(os.Stderr, "consul", 0))
 	if err != nil {
-		logger.Errorf("start(%v,%v,%v) watch failed:%v", w.name, w.dc, w.tags, err)
+		w.option().logger.Error("consul.Watch.start() failed", "service", w.name, "error", err)
 		return errors.Wrap(err)
 	}
 	return nil
@@ -96,10 +133,13 @@ func (w *Watch) Handler(idx uint64, services interface{}) {
 		entries: entries,
 	}
 	if w.isDebug() {
-		logger.Debugf("watch.Handler(%s, %d): services: %v", w.name, idx, len(entries))
+		w.option().logger.Debug("consul.Watch.Handler()", "service", w.name, "index", idx, "services", len(entries))
 	}
 
-	w.watchChans <- wc
+	select {
+	case w.watchChans <- wc:
+	case <-w.ctx.Done():
+	}
 }
 
 func (w *Watch) CheckDegrade(entries []*api.ServiceEntry) ([]*api.ServiceEntry, error) {
@@ -112,7 +152,7 @@ func (w *Watch) CheckDegrade(entries []*api.ServiceEntry) ([]*api.ServiceEntry,
 			if errors.Is(err, errors.ErrDegradePass) {
 				continue
 			}
-			logger.Infof("watch.Handler(%s): degrader: %T, services: %v", w.name, degrade, len(entries))
+			w.option().logger.Info("consul.Watch.Handler() degraded", "service", w.name, "degrader", fmt.Sprintf("%T", degrade), "services", len(entries))
 		}
 		return newEntries, nil
 	}
@@ -120,13 +160,30 @@ func (w *Watch) CheckDegrade(entries []*api.ServiceEntry) ([]*api.ServiceEntry,
 	return newEntries, err
 }
 
+// Stop stops the watch plan and cancels the blocking query in flight.
 func (w *Watch) Stop() {
-	w.plan.Stop()
+	w.cancel()
+
+	w.mux.Lock()
+	if w.plan != nil {
+		w.plan.Stop()
+	}
+	w.mux.Unlock()
+}
+
+// Wait blocks until the watch plan exited or ctx done.
+func (w *Watch) Wait(ctx context.Context) error {
+	select {
+	case <-w.done:
+		return nil
+	case <-ctx.Done():
+		return errors.Wrap(ctx.Err())
+	}
 }
 
 func (w *Watch) ServiceWatch() watch.WatcherFunc {
 	return func(p *watch.Plan) (watch.BlockingParamVal, interface{}, error) {
-		ctx, cancel := context.WithCancel(context.Background())
+		ctx, cancel := context.WithCancel(w.ctx)
 		defer cancel()
 
 		opts := &api.QueryOptions{
@@ -140,25 +197,36 @@ func (w *Watch) ServiceWatch() watch.WatcherFunc {
 
 		nodes, meta, err := w.consul().Health().ServiceMultipleTags(w.name, w.tags, false, opts)
 		if err != nil {
+			if w.ctx.Err() == nil {
+				w.option().metrics.WatchError(w.key(), err)
+				w.option().hooks.WatchError(&registry.WatchErrorEvent{
+					Key:       w.key(),
+					Adapter:   AdapterName,
+					LastIndex: w.lastIndex,
+					Err:       err,
+				})
+			}
 			return nil, nil, err
 		}
 
 		if w.isDebug() {
-			logger.Debugf("watch.WatcherFunc(%s): prev index: %v, last index: %v, nodes: %d",
-				w.name, w.lastIndex, meta.LastIndex, len(nodes))
+			w.option().logger.Debug("consul.Watch.WatcherFunc()", "service", w.name, "prev_index", w.lastIndex, "last_index", meta.LastIndex, "nodes", len(nodes))
 		}
 
 		if w.lastIndex == 0 {
-			logger.Infof("watch.WatcherFunc(%s): prev index: %v, last index: %v, nodes: %d",
-				w.name, w.lastIndex, meta.LastIndex, len(nodes))
+			w.option().logger.Info("consul.Watch.WatcherFunc() first result", "service", w.name, "prev_index", w.lastIndex, "last_index", meta.LastIndex, "nodes", len(nodes))
 
 			w.Handler(meta.LastIndex, nodes)
 		}
 
+		if meta.LastIndex != w.lastIndex {
+			w.option().metrics.WatchIndex(w.key(), meta.LastIndex)
+		}
+
 		w.backoff(meta.LastIndex)
 
 		//update lastIndex
-		w.lastIndex = meta.LastIndex
+		atomic.StoreUint64(&w.lastIndex, meta.LastIndex)
 
 		return watch.WaitIndexVal(w.lastIndex), nodes, err
 	}
@@ -194,11 +262,21 @@ func (w *Watch) backoff(curIndex uint64) {
 	}
 
 	if w.delay.Seconds() > 0 {
+		w.option().metrics.WatchBackoff(w.key(), w.delay)
+
 		if w.isDebug() {
-			logger.Debugf("watch.backoff(%s): triggered with delay: %v", w.name, w.delay)
+			w.option().logger.Debug("consul.Watch.backoff() triggered", "service", w.name, "delay", w.delay)
 		}
 
 		// sleep with sliding duration
-		time.Sleep(SlidingDuration(w.delay))
+		select {
+		case <-time.After(SlidingDuration(w.delay)):
+		case <-w.ctx.Done():
+		}
 	}
 }
+
+// LastIndex returns index of the last blocking query.
+func (w *Watch) LastIndex() uint64 {
+	return atomic.LoadUint64(&w.lastIndex)
+}
@@ -0,0 +1,75 @@
+// Package debug provides a http.Handler serving live state of discovery, it is designed for mounting on admin port.
+package debug
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/leon-gopher/discovery"
+)
+
+// Handler serves snapshot of a Registry as HTML, or JSON if requested with ?format=json or Accept: application/json.
+type Handler struct {
+	registry *discovery.Registry
+}
+
+// NewHandler creates a *Handler of the registry.
+func NewHandler(r *discovery.Registry) *Handler {
+	return &Handler{
+		registry: r,
+	}
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	snapshot := h.Snapshot(req)
+
+	if wantJSON(req) {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(snapshot); err != nil {
+			h.registry.Logger().Error("debug.ServeHTTP() failed", "error", err)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := page.Execute(w, snapshot); err != nil {
+		h.registry.Logger().Error("debug.ServeHTTP() failed", "error", err)
+	}
+}
+
+// Snapshot collects status of services known and services registered, checks are queried with ctx of req.
+func (h *Handler) Snapshot(req *http.Request) *Snapshot {
+	snapshot := &Snapshot{
+		Time:     time.Now(),
+		Services: h.registry.Status(),
+	}
+
+	for _, sr := range h.registry.Registered() {
+		registered := &Registered{
+			Service: sr.Service(),
+		}
+
+		checks, err := sr.Checks(req.Context())
+		if err != nil {
+			registered.Error = err.Error()
+		}
+		registered.Checks = checks
+
+		snapshot.Registered = append(snapshot.Registered, registered)
+	}
+
+	return snapshot
+}
+
+func wantJSON(req *http.Request) bool {
+	if req.URL.Query().Get("format") == "json" {
+		return true
+	}
+
+	return strings.Contains(req.Header.Get("Accept"), "application/json")
+}
@@ -0,0 +1,79 @@
+package debug
+
+import (
+	"html/template"
+	"time"
+)
+
+var page = template.Must(template.New("debug").Funcs(template.FuncMap{
+	"age": func(t time.Time) string {
+		if t.IsZero() {
+			return "-"
+		}
+
+		return time.Since(t).Truncate(time.Second).String()
+	},
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>discovery</title>
+<style>
+body { font-family: monospace; font-size: 13px; }
+table { border-collapse: collapse; margin-bottom: 16px; }
+th, td { border: 1px solid #ccc; padding: 2px 6px; text-align: left; vertical-align: top; }
+.degraded, .critical { color: #c00; }
+.warning { color: #c80; }
+.passing { color: #080; }
+</style>
+</head>
+<body>
+<p>{{.Time.Format "2006-01-02 15:04:05"}}, <a href="?format=json">json</a></p>
+
+<h2>Services</h2>
+<table>
+<tr><th>key</th><th>adapter</th><th>source</th><th>instances</th><th>index</th><th>updated</th><th>degrade</th><th>dump</th><th>dump age</th></tr>
+{{range .Services}}
+<tr>
+<td>{{.Key.ToString}}</td>
+<td>{{.Adapter}}</td>
+<td>{{.Source}}</td>
+<td>{{.Instances}}</td>
+<td>{{.LastIndex}}</td>
+<td>{{age .UpdatedAt}}</td>
+<td>{{if .Degraded}}<span class="degraded">degraded</span>{{else}}-{{end}} {{.TotalNodes}} * {{.Threshold}}</td>
+<td>{{.DumpFile}}</td>
+<td>{{age .DumpModifiedAt}}</td>
+</tr>
+{{if .Services}}
+<tr><td></td><td colspan="8">
+<table>
+<tr><th>id</th><th>addr</th><th>weight</th><th>tags</th><th>meta</th></tr>
+{{range .Services}}
+<tr><td>{{.ID}}</td><td>{{.Addr}}</td><td>{{.Weight}}</td><td>{{.Tags}}</td><td>{{range $k, $v := .Meta}}{{$k}}={{$v}} {{end}}</td></tr>
+{{end}}
+</table>
+</td></tr>
+{{end}}
+{{end}}
+</table>
+
+<h2>Registered</h2>
+<table>
+<tr><th>id</th><th>addr</th><th>weight</th><th>tags</th><th>checks</th></tr>
+{{range .Registered}}
+<tr>
+<td>{{.Service.ServiceID}}</td>
+<td>{{.Service.Addr}}</td>
+<td>{{.Service.Weight}}</td>
+<td>{{.Service.Tags}}</td>
+<td>
+{{if .Error}}<span class="critical">{{.Error}}</span><br>{{end}}
+{{range .Checks}}<span class="{{.Status}}">{{.Status}}</span> {{.ID}} ({{.Type}}) {{.Output}}<br>{{end}}
+</td>
+</tr>
+{{end}}
+</table>
+</body>
+</html>
+`))
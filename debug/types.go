@@ -0,0 +1,21 @@
+package debug
+
+import (
+	"time"
+
+	"github.com/leon-gopher/discovery/registry"
+)
+
+// Snapshot is live state of a Registry served by Handler.
+type Snapshot struct {
+	Time       time.Time                 `json:"time"`
+	Services   []*registry.ServiceStatus `json:"services"`
+	Registered []*Registered             `json:"registered"`
+}
+
+// Registered is a local service registered through the Registry with status of its health checks.
+type Registered struct {
+	Service *registry.Service       `json:"service"`
+	Checks  []*registry.CheckStatus `json:"checks"`
+	Error   string                  `json:"error,omitempty"`
+}
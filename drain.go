@@ -0,0 +1,126 @@
+package discovery
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/leon-gopher/discovery/registry"
+)
+
+type DrainOption func(*drainOption)
+
+type drainOption struct {
+	reason       string
+	gracePeriod  time.Duration
+	pollInterval time.Duration
+	untilRemoved bool
+}
+
+// WithDrainReason sets reason of maintenance mode.
+func WithDrainReason(reason string) DrainOption {
+	return func(o *drainOption) {
+		o.reason = reason
+	}
+}
+
+// WithDrainGracePeriod sets max waiting after draining, default to DefaultDrainGracePeriod.
+func WithDrainGracePeriod(d time.Duration) DrainOption {
+	return func(o *drainOption) {
+		o.gracePeriod = d
+	}
+}
+
+// WithDrainPollInterval sets interval of checking whether the service removed from passing results.
+func WithDrainPollInterval(d time.Duration) DrainOption {
+	return func(o *drainOption) {
+		if d > 0 {
+			o.pollInterval = d
+		}
+	}
+}
+
+// WithDrainUntilRemoved stops waiting once the service removed from passing results, otherwise waits the whole grace period.
+func WithDrainUntilRemoved(untilRemoved bool) DrainOption {
+	return func(o *drainOption) {
+		o.untilRemoved = untilRemoved
+	}
+}
+
+// Drain removes traffic of the service gracefully, it
+//  1. puts the service into maintenance mode, or fails its TTL checks and readiness check;
+//  2. waits the grace period, or until the service removed from passing results;
+//  3. deregisters the service.
+//
+// The whole grace period is waited if no registrator drained the service, so clients with cached endpoints have
+// time to refresh. The waiting is bounded by ctx, and the service is always deregistered.
+func (sr *ServiceRegistrator) Drain(ctx context.Context, opts ...DrainOption) error {
+	o := &drainOption{
+		reason:       DefaultDrainReason,
+		gracePeriod:  DefaultDrainGracePeriod,
+		pollInterval: DefaultDrainPollInterval,
+		untilRemoved: true,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	// heartbeat would report TTL checks passing again
+	sr.stopHeartbeat()
+	sr.unready(o.reason)
+
+	var drainers []registry.Drainer
+	for _, register := range sr.registrators {
+		drainer, ok := register.(registry.Drainer)
+		if !ok {
+			continue
+		}
+
+		err := drainer.Drain(ctx, sr.Service(), o.reason)
+		if err != nil {
+			sr.log().Error("ServiceRegistrator.Drain() failed", "service", sr.Service().ServiceID(), "registrator", fmt.Sprintf("%T", register), "error", err)
+			continue
+		}
+
+		drainers = append(drainers, drainer)
+	}
+
+	sr.waitDrained(ctx, drainers, o)
+
+	return sr.Deregister()
+}
+
+func (sr *ServiceRegistrator) waitDrained(ctx context.Context, drainers []registry.Drainer, o *drainOption) {
+	ctx, cancel := context.WithTimeout(ctx, o.gracePeriod)
+	defer cancel()
+
+	ticker := time.NewTicker(o.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+
+		// nothing to poll without drainers
+		if !o.untilRemoved || len(drainers) == 0 {
+			continue
+		}
+
+		drained := true
+		for _, drainer := range drainers {
+			ok, err := drainer.IsDrained(ctx, sr.Service())
+			if err != nil || !ok {
+				drained = false
+				break
+			}
+		}
+
+		if drained {
+			sr.log().Info("ServiceRegistrator.Drain() removed from passing results", "service", sr.Service().ServiceID())
+			return
+		}
+	}
+}
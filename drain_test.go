@@ -0,0 +1,201 @@
+package discovery
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/leon-gopher/discovery/errors"
+	"github.com/leon-gopher/discovery/logger"
+	"github.com/leon-gopher/discovery/registry"
+)
+
+// recordingRegistrator records calls in order, e.g. "register", "ttl:passing" and "check:critical".
+type recordingRegistrator struct {
+	mux   sync.Mutex
+	calls []string
+
+	drainErr error
+	// IsDrained returns true from the nth call
+	drainedAt int
+	polls     int
+}
+
+func (rr *recordingRegistrator) record(call string) {
+	rr.mux.Lock()
+	rr.calls = append(rr.calls, call)
+	rr.mux.Unlock()
+}
+
+func (rr *recordingRegistrator) history() string {
+	rr.mux.Lock()
+	defer rr.mux.Unlock()
+
+	return strings.Join(rr.calls, ",")
+}
+
+func (rr *recordingRegistrator) Register(*registry.Service, ...registry.RegistratorOption) error {
+	rr.record("register")
+	return nil
+}
+
+func (rr *recordingRegistrator) Deregister(*registry.Service, ...registry.RegistratorOption) error {
+	rr.record("deregister")
+	return nil
+}
+
+func (rr *recordingRegistrator) Drain(context.Context, *registry.Service, string) error {
+	rr.record("drain")
+	return rr.drainErr
+}
+
+func (rr *recordingRegistrator) IsDrained(context.Context, *registry.Service) (bool, error) {
+	rr.mux.Lock()
+	defer rr.mux.Unlock()
+
+	rr.polls++
+	return rr.polls >= rr.drainedAt, nil
+}
+
+func (rr *recordingRegistrator) UpdateCheck(_ *registry.Service, _ string, status registry.HealthStatus, _ string) error {
+	rr.record("check:" + string(status))
+	return nil
+}
+
+func (rr *recordingRegistrator) UpdateTTL(_ *registry.Service, status registry.HealthStatus, _ string) error {
+	rr.record("ttl:" + string(status))
+	return nil
+}
+
+// checkOnlyRegistrator hides Drainer and TTLUpdater of recordingRegistrator.
+type checkOnlyRegistrator struct {
+	registry.Registrator
+	registry.CheckUpdater
+}
+
+func TestDrain(t *testing.T) {
+	const grace = 200 * time.Millisecond
+
+	cases := []struct {
+		name         string
+		drainErr     error
+		drainedAt    int
+		noDrainer    bool
+		untilRemoved bool
+		timeout      time.Duration
+		// whether the whole grace period is waited
+		waited  bool
+		history string
+	}{
+		{
+			name:         "removed from passing results",
+			drainedAt:    2,
+			untilRemoved: true,
+			history:      "register,check:critical,drain,deregister",
+		},
+		{
+			name:         "grace period",
+			drainedAt:    1,
+			untilRemoved: false,
+			waited:       true,
+			history:      "register,check:critical,drain,deregister",
+		},
+		{
+			name:         "drain failed",
+			drainErr:     errors.ErrUnavailable,
+			untilRemoved: true,
+			waited:       true,
+			history:      "register,check:critical,drain,deregister",
+		},
+		{
+			name:         "no drainer",
+			noDrainer:    true,
+			untilRemoved: true,
+			waited:       true,
+			history:      "register,check:critical,deregister",
+		},
+		{
+			name:         "ctx done",
+			drainedAt:    1000,
+			untilRemoved: true,
+			timeout:      20 * time.Millisecond,
+			history:      "register,check:critical,drain,deregister",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			rr := &recordingRegistrator{drainErr: tc.drainErr, drainedAt: tc.drainedAt}
+
+			var register registry.Registrator = rr
+			if tc.noDrainer {
+				register = checkOnlyRegistrator{Registrator: rr, CheckUpdater: rr}
+			}
+
+			r, err := NewRegistry(WithRegisters(register), WithLogger(logger.Nop()))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// never ready, the readiness check is failed once by draining
+			sr, err := r.Register(&registry.Service{ID: "svc-1", Name: "svc", IP: "10.0.0.1", Port: 80}, registry.WithReadiness(make(chan struct{})))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			ctx := context.Background()
+			if tc.timeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, tc.timeout)
+				defer cancel()
+			}
+
+			start := time.Now()
+			err = sr.Drain(ctx,
+				WithDrainGracePeriod(grace), WithDrainPollInterval(10*time.Millisecond), WithDrainUntilRemoved(tc.untilRemoved))
+			elapsed := time.Since(start)
+
+			if err != nil {
+				t.Fatalf("Drain(): %v", err)
+			}
+			if waited := elapsed >= grace; waited != tc.waited {
+				t.Fatalf("Drain() took %v, expected grace period waited %v", elapsed, tc.waited)
+			}
+			if history := rr.history(); history != tc.history {
+				t.Fatalf("history: %s, expected %s", history, tc.history)
+			}
+			if len(r.Registered()) != 0 {
+				t.Fatal("drained service is still tracked")
+			}
+		})
+	}
+}
+
+func TestDrainStopsHeartbeat(t *testing.T) {
+	rr := &recordingRegistrator{drainedAt: 1}
+
+	r, err := NewRegistry(WithRegisters(rr), WithLogger(logger.Nop()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sr, err := r.Register(&registry.Service{ID: "svc-1", Name: "svc", IP: "10.0.0.1", Port: 80})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sr.Heartbeat(5*time.Millisecond, func() error { return nil })
+	time.Sleep(20 * time.Millisecond)
+
+	if err := sr.Drain(context.Background(), WithDrainPollInterval(10*time.Millisecond)); err != nil {
+		t.Fatalf("Drain(): %v", err)
+	}
+
+	// no passing reported once draining started
+	history := rr.history()
+	if !strings.HasSuffix(history, ",drain,deregister") || strings.Contains(history[strings.Index(history, "drain"):], "ttl:") {
+		t.Fatalf("history: %s", history)
+	}
+}
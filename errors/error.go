@@ -15,6 +15,7 @@ var (
 	ErrNotFound               = New("not found")
 	ErrNilConfig              = New("nil config")
 	ErrBalancerNotImplemented = New("algorithm has not implemented")
+	ErrNotSupported           = New("not supported")
 )
 
 type wrapError struct {
@@ -61,3 +62,7 @@ func Errorf(format string, v ...interface{}) error {
 func Is(err, target error) bool {
 	return xerrors.Is(err, target)
 }
+
+func As(err error, target interface{}) bool {
+	return xerrors.As(err, target)
+}
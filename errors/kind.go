@@ -0,0 +1,131 @@
+package errors
+
+import (
+	"context"
+	"net/http"
+)
+
+// Kind classifies errors, see KindOf.
+type Kind int
+
+const (
+	KindUnknown Kind = iota
+	// KindNotFound means the service has no instance or dump.
+	KindNotFound
+	// KindUnavailable means the registry is unreachable or overloaded.
+	KindUnavailable
+	// KindTimeout means the request to registry timed out.
+	KindTimeout
+	// KindCanceled means the request is canceled by caller.
+	KindCanceled
+	// KindArgument means invalid options or arguments.
+	KindArgument
+	// KindPermission means the request is denied by registry, e.g. ACL.
+	KindPermission
+	// KindNotSupported means the adapter does not support the operation.
+	KindNotSupported
+)
+
+var (
+	ErrUnavailable = New("registry unavailable")
+	ErrTimeout     = New("registry timeout")
+	ErrCanceled    = New("canceled")
+	ErrPermission  = New("permission denied")
+)
+
+var kindErrors = map[Kind]error{
+	KindNotFound:     ErrNotFound,
+	KindUnavailable:  ErrUnavailable,
+	KindTimeout:      ErrTimeout,
+	KindCanceled:     ErrCanceled,
+	KindArgument:     ErrArgument,
+	KindPermission:   ErrPermission,
+	KindNotSupported: ErrNotSupported,
+}
+
+func (k Kind) String() string {
+	if err, ok := kindErrors[k]; ok {
+		return err.Error()
+	}
+
+	return "unknown"
+}
+
+// Sentinel returns the sentinel error of the kind, it is nil for KindUnknown.
+func (k Kind) Sentinel() error {
+	return kindErrors[k]
+}
+
+// Retryable reports whether the same request may succeed if retried immediately.
+func (k Kind) Retryable() bool {
+	switch k {
+	case KindUnavailable, KindTimeout:
+		return true
+	}
+
+	return false
+}
+
+// Temporary reports whether the error may resolve by itself later, e.g. instances registered.
+func (k Kind) Temporary() bool {
+	return k.Retryable() || k == KindNotFound
+}
+
+// KindOfStatus classifies HTTP status code returned by registry.
+func KindOfStatus(code int) Kind {
+	switch {
+	case code == http.StatusNotFound:
+		return KindNotFound
+	case code == http.StatusUnauthorized, code == http.StatusForbidden:
+		return KindPermission
+	case code == http.StatusRequestTimeout, code == http.StatusGatewayTimeout:
+		return KindTimeout
+	case code == http.StatusTooManyRequests, code >= http.StatusInternalServerError:
+		return KindUnavailable
+	case code >= http.StatusBadRequest:
+		return KindArgument
+	}
+
+	return KindUnknown
+}
+
+type kinder interface {
+	ErrorKind() Kind
+}
+
+// KindOf returns kind of the first typed error in chain of err, or kind of sentinel errors.
+func KindOf(err error) Kind {
+	if err == nil {
+		return KindUnknown
+	}
+
+	var ke kinder
+	if As(err, &ke) {
+		return ke.ErrorKind()
+	}
+
+	switch {
+	case Is(err, context.Canceled):
+		return KindCanceled
+	case Is(err, context.DeadlineExceeded):
+		return KindTimeout
+	}
+
+	for kind, sentinel := range kindErrors {
+		if Is(err, sentinel) {
+			return kind
+		}
+	}
+
+	return KindUnknown
+}
+
+// IsRetryable reports whether err is worth retrying immediately, e.g. registry unavailable or timeout.
+func IsRetryable(err error) bool {
+	return KindOf(err).Retryable()
+}
+
+// IsTemporary reports whether err may resolve by itself later, it includes retryable errors and not found.
+func IsTemporary(err error) bool {
+	return KindOf(err).Temporary()
+}
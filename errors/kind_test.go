@@ -0,0 +1,128 @@
+package errors
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+type kindError struct {
+	kind Kind
+	err  error
+}
+
+func (e *kindError) Error() string {
+	return e.kind.String()
+}
+
+func (e *kindError) ErrorKind() Kind {
+	return e.kind
+}
+
+func (e *kindError) Unwrap() error {
+	return e.err
+}
+
+func TestKindOfStatus(t *testing.T) {
+	cases := []struct {
+		code int
+		kind Kind
+	}{
+		{code: http.StatusOK, kind: KindUnknown},
+		{code: http.StatusMovedPermanently, kind: KindUnknown},
+		{code: http.StatusBadRequest, kind: KindArgument},
+		{code: http.StatusUnauthorized, kind: KindPermission},
+		{code: http.StatusForbidden, kind: KindPermission},
+		{code: http.StatusNotFound, kind: KindNotFound},
+		{code: http.StatusRequestTimeout, kind: KindTimeout},
+		{code: http.StatusConflict, kind: KindArgument},
+		{code: http.StatusTooManyRequests, kind: KindUnavailable},
+		{code: http.StatusInternalServerError, kind: KindUnavailable},
+		{code: http.StatusBadGateway, kind: KindUnavailable},
+		{code: http.StatusServiceUnavailable, kind: KindUnavailable},
+		{code: http.StatusGatewayTimeout, kind: KindTimeout},
+	}
+
+	for _, tc := range cases {
+		t.Run(http.StatusText(tc.code), func(t *testing.T) {
+			if kind := KindOfStatus(tc.code); kind != tc.kind {
+				t.Fatalf("KindOfStatus(%d): %v, expected %v", tc.code, kind, tc.kind)
+			}
+		})
+	}
+}
+
+func TestKindOf(t *testing.T) {
+	cases := []struct {
+		name      string
+		err       error
+		kind      Kind
+		retryable bool
+		temporary bool
+	}{
+		{name: "nil", err: nil, kind: KindUnknown},
+		{name: "plain", err: New("boom"), kind: KindUnknown},
+		{name: "not found", err: ErrNotFound, kind: KindNotFound, temporary: true},
+		{name: "wrapped not found", err: Wrap(ErrNotFound), kind: KindNotFound, temporary: true},
+		{name: "errorf not found", err: fmt.Errorf("lookup: %w", ErrNotFound), kind: KindNotFound, temporary: true},
+		{name: "unavailable", err: Wrap(ErrUnavailable), kind: KindUnavailable, retryable: true, temporary: true},
+		{name: "timeout", err: ErrTimeout, kind: KindTimeout, retryable: true, temporary: true},
+		{name: "argument", err: Wrap(ErrArgument), kind: KindArgument},
+		{name: "permission", err: ErrPermission, kind: KindPermission},
+		{name: "not supported", err: Wrap(ErrNotSupported), kind: KindNotSupported},
+		{name: "canceled", err: Wrap(context.Canceled), kind: KindCanceled},
+		{name: "deadline exceeded", err: fmt.Errorf("get: %w", context.DeadlineExceeded), kind: KindTimeout, retryable: true, temporary: true},
+		{name: "typed", err: &kindError{kind: KindUnavailable}, kind: KindUnavailable, retryable: true, temporary: true},
+		{name: "wrapped typed", err: Wrap(&kindError{kind: KindPermission}), kind: KindPermission},
+		{
+			// the typed error takes precedence over sentinels it wraps
+			name: "typed over sentinel",
+			err:  Wrap(&kindError{kind: KindArgument, err: ErrTimeout}),
+			kind: KindArgument,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if kind := KindOf(tc.err); kind != tc.kind {
+				t.Fatalf("KindOf(%v): %v, expected %v", tc.err, kind, tc.kind)
+			}
+			if retryable := IsRetryable(tc.err); retryable != tc.retryable {
+				t.Fatalf("IsRetryable(%v): %v, expected %v", tc.err, retryable, tc.retryable)
+			}
+			if temporary := IsTemporary(tc.err); temporary != tc.temporary {
+				t.Fatalf("IsTemporary(%v): %v, expected %v", tc.err, temporary, tc.temporary)
+			}
+		})
+	}
+}
+
+func TestKindSentinel(t *testing.T) {
+	cases := []struct {
+		kind     Kind
+		sentinel error
+	}{
+		{kind: KindUnknown, sentinel: nil},
+		{kind: KindNotFound, sentinel: ErrNotFound},
+		{kind: KindUnavailable, sentinel: ErrUnavailable},
+		{kind: KindTimeout, sentinel: ErrTimeout},
+		{kind: KindCanceled, sentinel: ErrCanceled},
+		{kind: KindArgument, sentinel: ErrArgument},
+		{kind: KindPermission, sentinel: ErrPermission},
+		{kind: KindNotSupported, sentinel: ErrNotSupported},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.kind.String(), func(t *testing.T) {
+			if sentinel := tc.kind.Sentinel(); sentinel != tc.sentinel {
+				t.Fatalf("Sentinel(): %v, expected %v", sentinel, tc.sentinel)
+			}
+
+			// sentinels round trip to their kinds
+			if tc.sentinel != nil && KindOf(Wrap(tc.sentinel)) != tc.kind {
+				t.Fatalf("KindOf(%v): %v, expected %v", tc.sentinel, KindOf(tc.sentinel), tc.kind)
+			}
+		})
+	}
+}
@@ -0,0 +1,4 @@
+package file
+
+// AdapterName is name of the adapter reported by registry.Error.
+const AdapterName = "file"
@@ -47,16 +47,57 @@ func (f *File) GetServices(name string, opts ...registry.DiscoveryOption) ([]*re
 
 		iface, ok = f.store.Load(key)
 		if !ok {
-			return nil, errors.Wrap(errors.ErrNotFound)
+			return nil, errors.Wrap(registry.NewError("GetServices", AdapterName, key, errors.ErrNotFound))
 		}
 	}
 
 	adapter, ok := iface.(registry.Discovery)
 	if ok {
-		return adapter.GetServices(name, opts...)
+		services, err := adapter.GetServices(name, opts...)
+		if err != nil {
+			return nil, errors.Wrap(registry.NewError("GetServices", AdapterName, key, err))
+		}
+
+		return services, nil
 	}
 
-	return nil, errors.Wrap(errors.ErrNotFound)
+	return nil, errors.Wrap(registry.NewError("GetServices", AdapterName, key, errors.ErrNotFound))
+}
+
+// Status reports services loaded from dump, with modify time of dump files.
+func (f *File) Status() []*registry.ServiceStatus {
+	var statuses []*registry.ServiceStatus
+
+	f.store.Range(func(_, value interface{}) bool {
+		reporter, ok := value.(registry.StatusReporter)
+		if !ok {
+			return true
+		}
+
+		for _, status := range reporter.Status() {
+			status.Adapter = AdapterName
+			status.Source = registry.SourceFileDump
+
+			if namer, ok := f.loader.(filenamer); ok {
+				status.DumpFile = namer.Filename(status.Key)
+			}
+			if modifier, ok := f.loader.(lastModifier); ok {
+				if modified, err := modifier.LastModify(status.Key); err == nil {
+					status.DumpModifiedAt = modified
+				}
+			}
+
+			statuses = append(statuses, status)
+		}
+
+		return true
+	})
+
+	return statuses
+}
+
+func (f *File) Name() string {
+	return AdapterName
 }
 
 func (f *File) Watch(w registry.Watcher) {}
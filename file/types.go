@@ -1,7 +1,21 @@
 package file
 
-import "github.com/leon-gopher/discovery/registry"
+import (
+	"time"
+
+	"github.com/leon-gopher/discovery/registry"
+)
 
 type Loader interface {
 	Load(registry.ServiceKey) ([]*registry.Service, error)
 }
+
+// lastModifier is implemented by loader which knows modify time of dumps, e.g. dumper.Dumper.
+type lastModifier interface {
+	LastModify(registry.ServiceKey) (time.Time, error)
+}
+
+// filenamer is implemented by loader which stores dumps in files, e.g. *file.Dumper.
+type filenamer interface {
+	Filename(registry.ServiceKey) string
+}
@@ -5,15 +5,18 @@ go 1.14
 require (
 	github.com/golib/zerolog v1.19.0
 	github.com/google/uuid v1.1.1
-	github.com/hashicorp/consul/api v1.7.0
+	github.com/hashicorp/consul/api v1.12.0
 	github.com/hashicorp/go-sockaddr v1.0.0
 	github.com/hashicorp/go.net v0.0.1 // indirect
 	github.com/kami-zh/go-capturer v0.0.0-20171211120116-e492ea43421d
 	github.com/mitchellh/gox v0.4.0 // indirect
 	github.com/mitchellh/iochan v1.0.0 // indirect
-	github.com/stretchr/testify v1.4.0
-	golang.org/x/sync v0.0.0-20190911185100-cd5d95a43a6e
-	golang.org/x/xerrors v0.0.0-20191011141410-1b5146add898
+	github.com/prometheus/client_golang v1.5.1
+	github.com/stretchr/testify v1.7.0
+	go.opentelemetry.io/otel v1.0.0
+	go.opentelemetry.io/otel/trace v1.0.0
+	golang.org/x/sync v0.0.0-20210220032951-036812b2e83c
+	golang.org/x/xerrors v0.0.0-20191204190536-9bdfabe68543
 	google.golang.org/grpc v1.24.0
 	gopkg.in/yaml.v2 v2.2.8
 )
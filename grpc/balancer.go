@@ -0,0 +1,135 @@
+package grpc
+
+import (
+	"context"
+	"net"
+	"strconv"
+
+	"github.com/leon-gopher/discovery/balancer"
+	"github.com/leon-gopher/discovery/registry"
+	grpcbalancer "google.golang.org/grpc/balancer"
+	"google.golang.org/grpc/balancer/base"
+	"google.golang.org/grpc/resolver"
+)
+
+func init() {
+	names := []string{
+		balancer.RoundRobin, balancer.WeightedRoundRobin, balancer.WeightedRandom, balancer.P2C,
+		balancer.RingHash, balancer.BoundedRingHash, balancer.Maglev, balancer.Locality,
+	}
+	for _, name := range names {
+		grpcbalancer.Register(NewBalancerBuilder(BalancerName(name), balancer.Get(name)))
+	}
+}
+
+type hashKeyCtx struct{}
+
+// WithHashKey returns a context with key used by hash based balancers for sticky routing, it's the full method name by default.
+func WithHashKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, hashKeyCtx{}, key)
+}
+
+// BalancerName returns name of grpc balancer registered for the balancer given, e.g. grpc.WithBalancerName(BalancerName(balancer.P2C)).
+func BalancerName(name string) string {
+	return Scheme + "_" + name
+}
+
+// NewBalancerBuilder creates a grpc balancer.Builder which picks SubConn with pickers of balancer.Builder given.
+func NewBalancerBuilder(name string, builder balancer.Builder) grpcbalancer.Builder {
+	return &balancerBuilder{
+		name:    name,
+		builder: builder,
+	}
+}
+
+// balancerBuilder builds pickers of each ClientConn with the service key of its target, see balancer.KeyBuilder.
+type balancerBuilder struct {
+	name    string
+	builder balancer.Builder
+}
+
+func (bb *balancerBuilder) Name() string {
+	return bb.name
+}
+
+func (bb *balancerBuilder) Build(cc grpcbalancer.ClientConn, opts grpcbalancer.BuildOptions) grpcbalancer.Balancer {
+	pb := &pickerBuilder{
+		builder: bb.builder,
+	}
+
+	name, tags, dc, err := ParseTarget(opts.Target)
+	if err == nil {
+		pb.key = registry.NewServiceKey(name, tags, dc)
+	}
+
+	return base.NewBalancerBuilderWithConfig(bb.name, pb, base.Config{HealthCheck: true}).Build(cc, opts)
+}
+
+type pickerBuilder struct {
+	builder balancer.Builder
+	key     registry.ServiceKey
+}
+
+func (pb *pickerBuilder) Build(readySCs map[resolver.Address]grpcbalancer.SubConn) grpcbalancer.Picker {
+	if len(readySCs) == 0 {
+		return base.NewErrPicker(grpcbalancer.ErrNoSubConnAvailable)
+	}
+
+	services := make([]*registry.Service, 0, len(readySCs))
+	subConns := make(map[*registry.Service]grpcbalancer.SubConn, len(readySCs))
+	for addr, sc := range readySCs {
+		service := newService(addr)
+
+		services = append(services, service)
+		subConns[service] = sc
+	}
+
+	return &picker{
+		picker:   balancer.BuildKey(pb.builder, pb.key, services),
+		subConns: subConns,
+	}
+}
+
+type picker struct {
+	picker   balancer.Picker
+	subConns map[*registry.Service]grpcbalancer.SubConn
+}
+
+func (p *picker) Pick(ctx context.Context, opts grpcbalancer.PickOptions) (grpcbalancer.SubConn, func(grpcbalancer.DoneInfo), error) {
+	key, ok := ctx.Value(hashKeyCtx{}).(string)
+	if !ok {
+		key = opts.FullMethodName
+	}
+
+	service, done, err := p.picker.Pick(balancer.PickInfo{
+		Key: key,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return p.subConns[service], func(info grpcbalancer.DoneInfo) {
+		done(info.Err)
+	}, nil
+}
+
+// newService converts resolver address back to service with its Metadata.
+func newService(addr resolver.Address) *registry.Service {
+	service := &registry.Service{
+		Name: addr.ServerName,
+	}
+
+	host, port, err := net.SplitHostPort(addr.Addr)
+	if err == nil {
+		service.IP = host
+		service.Port, _ = strconv.Atoi(port)
+	}
+
+	if md, ok := addr.Metadata.(*Metadata); ok {
+		service.ID = md.ID
+		service.Weight = md.Weight
+		service.Meta = md.Meta
+	}
+
+	return service
+}
@@ -0,0 +1,84 @@
+package grpc
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/leon-gopher/discovery"
+	"github.com/leon-gopher/discovery/errors"
+	"github.com/leon-gopher/discovery/registry"
+	"google.golang.org/grpc/resolver"
+)
+
+// Register registers a grpc resolver builder of dis scheme backed by the *discovery.Registry given.
+func Register(r *discovery.Registry) {
+	resolver.Register(NewBuilder(r))
+}
+
+type builder struct {
+	registry *discovery.Registry
+}
+
+// NewBuilder creates a resolver.Builder with the *discovery.Registry given.
+func NewBuilder(r *discovery.Registry) resolver.Builder {
+	return &builder{
+		registry: r,
+	}
+}
+
+func (b *builder) Scheme() string {
+	return Scheme
+}
+
+func (b *builder) Build(target resolver.Target, cc resolver.ClientConn, opts resolver.BuildOption) (resolver.Resolver, error) {
+	name, tags, dc, err := ParseTarget(target)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &discoveryResolver{
+		registry: b.registry,
+		cc:       cc,
+		name:     name,
+		tags:     tags,
+		dc:       dc,
+		key:      registry.NewServiceKey(name, tags, dc),
+	}
+
+	// watch before resolving, so no update missed in between. Resolving starts the watch of discoveries as well.
+	r.cancel = b.registry.WithWatcherFunc(r.key, registry.WatchFunc(r.watch))
+
+	err = r.resolve()
+	if err != nil {
+		r.cancel()
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// ParseTarget resolves service name, tags and dc from target endpoint formatted in <name>[?tag=a&tag=b&dc=dc2].
+func ParseTarget(target resolver.Target) (name string, tags []string, dc string, err error) {
+	endpoint := strings.TrimPrefix(target.Endpoint, "/")
+
+	var rawQuery string
+	if idx := strings.IndexByte(endpoint, '?'); idx >= 0 {
+		endpoint, rawQuery = endpoint[:idx], endpoint[idx+1:]
+	}
+
+	if len(endpoint) == 0 {
+		err = errors.Wrap(errors.ErrArgument)
+		return
+	}
+
+	query, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		err = errors.Wrap(err)
+		return
+	}
+
+	name = endpoint
+	tags = query[QueryTag]
+	dc = query.Get(QueryDC)
+	return
+}
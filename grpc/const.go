@@ -0,0 +1,11 @@
+package grpc
+
+const (
+	// Scheme is the grpc target scheme resolved by discovery, e.g. dis:///backend-grpc-service?tag=a&dc=dc2
+	Scheme = "dis"
+)
+
+const (
+	QueryTag = "tag"
+	QueryDC  = "dc"
+)
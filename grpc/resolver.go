@@ -0,0 +1,113 @@
+package grpc
+
+import (
+	"sync"
+
+	"github.com/leon-gopher/discovery"
+	"github.com/leon-gopher/discovery/registry"
+	"google.golang.org/grpc/resolver"
+)
+
+type discoveryResolver struct {
+	mux      sync.Mutex
+	registry *discovery.Registry
+	cc       resolver.ClientConn
+	name     string
+	tags     []string
+	dc       string
+	key      registry.ServiceKey
+	metas    map[string]*Metadata
+	closed   bool
+	cancel   func()
+	// increased by every watch update, lookups started before it are stale
+	version uint64
+}
+
+func (r *discoveryResolver) ResolveNow(resolver.ResolveNowOption) {
+	err := r.resolve()
+	if err != nil {
+		r.registry.Logger().Error("grpc.ResolveNow() failed", "service", r.key.ToString(), "error", err)
+	}
+}
+
+func (r *discoveryResolver) Close() {
+	r.mux.Lock()
+	r.closed = true
+	r.mux.Unlock()
+
+	r.cancel()
+}
+
+func (r *discoveryResolver) resolve() error {
+	r.mux.Lock()
+	version := r.version
+	r.mux.Unlock()
+
+	services, err := r.registry.LookupServices(r.name, registry.WithTags(r.tags), registry.WithDC(r.dc))
+	if err != nil {
+		return err
+	}
+
+	r.mux.Lock()
+	defer r.mux.Unlock()
+
+	// watch updates arrived during lookup are newer
+	if r.version != version {
+		return nil
+	}
+
+	r.update(services)
+	return nil
+}
+
+func (r *discoveryResolver) watch(_ registry.ServiceKey, services []*registry.Service) {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+
+	r.version++
+	r.update(services)
+}
+
+// update pushes services to the ClientConn, it must be called with r.mux held.
+func (r *discoveryResolver) update(services []*registry.Service) {
+	if r.closed {
+		return
+	}
+
+	r.cc.UpdateState(resolver.State{
+		Addresses: r.newAddresses(services),
+	})
+}
+
+// newAddresses converts services to resolver addresses with Metadata. It reuses *Metadata of unchanged
+// services, because grpc compares addresses by value and recreates SubConn for changed one.
+func (r *discoveryResolver) newAddresses(services []*registry.Service) []resolver.Address {
+	metas := make(map[string]*Metadata, len(services))
+
+	addrs := make([]resolver.Address, 0, len(services))
+	for _, service := range services {
+		if service == nil {
+			continue
+		}
+
+		md, ok := r.metas[service.ID]
+		if !ok || !md.equal(service) {
+			md = &Metadata{
+				ID:     service.ID,
+				Weight: service.Weight,
+				Meta:   service.Meta,
+			}
+		}
+		metas[service.ID] = md
+
+		addrs = append(addrs, resolver.Address{
+			Addr:       service.RemoteAddr(),
+			ServerName: service.Name,
+			Metadata:   md,
+		})
+	}
+
+	r.metas = metas
+
+	return addrs
+}
@@ -0,0 +1,140 @@
+package grpc
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/leon-gopher/discovery"
+	"github.com/leon-gopher/discovery/logger"
+	"github.com/leon-gopher/discovery/registry"
+	"google.golang.org/grpc/resolver"
+)
+
+// stubDiscovery serves services set, GetServices blocks until release closed if it's set.
+type stubDiscovery struct {
+	mux      sync.Mutex
+	services []*registry.Service
+	watcher  registry.Watcher
+	entered  chan struct{}
+	release  chan struct{}
+}
+
+func (d *stubDiscovery) GetServices(string, ...registry.DiscoveryOption) ([]*registry.Service, error) {
+	d.mux.Lock()
+	services, entered, release := d.services, d.entered, d.release
+	d.mux.Unlock()
+
+	if release != nil {
+		entered <- struct{}{}
+		<-release
+	}
+
+	return services, nil
+}
+
+func (d *stubDiscovery) Notify(registry.Event) {}
+
+func (d *stubDiscovery) Watch(w registry.Watcher) {
+	d.watcher = w
+}
+
+func (d *stubDiscovery) push(key registry.ServiceKey, services []*registry.Service) {
+	d.mux.Lock()
+	d.services = services
+	d.mux.Unlock()
+
+	d.watcher.Watch(key, services)
+}
+
+type stubClientConn struct {
+	resolver.ClientConn
+
+	states chan resolver.State
+}
+
+func (cc *stubClientConn) UpdateState(state resolver.State) {
+	cc.states <- state
+}
+
+func (cc *stubClientConn) expect(t *testing.T, addrs ...string) {
+	t.Helper()
+
+	select {
+	case state := <-cc.states:
+		if len(state.Addresses) != len(addrs) {
+			t.Fatalf("addresses: %v, expected %v", state.Addresses, addrs)
+		}
+		for i, addr := range state.Addresses {
+			if addr.Addr != addrs[i] {
+				t.Fatalf("addresses: %v, expected %v", state.Addresses, addrs)
+			}
+		}
+
+	case <-time.After(time.Second):
+		t.Fatalf("no update, expected %v", addrs)
+	}
+}
+
+func (cc *stubClientConn) expectNone(t *testing.T) {
+	t.Helper()
+
+	select {
+	case state := <-cc.states:
+		t.Fatalf("unexpected update: %v", state.Addresses)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestResolverSkipsStaleLookup(t *testing.T) {
+	key := registry.NewServiceKey("svc", nil, "")
+	v1 := []*registry.Service{{ID: "1", Name: "svc", IP: "10.0.0.1", Port: 80}}
+	v2 := []*registry.Service{{ID: "2", Name: "svc", IP: "10.0.0.2", Port: 80}}
+
+	disc := &stubDiscovery{
+		services: v1,
+		entered:  make(chan struct{}),
+		release:  make(chan struct{}),
+	}
+
+	reg, err := discovery.NewRegistry(discovery.WithDiscoveries(disc), discovery.WithLogger(logger.Nop()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cc := &stubClientConn{states: make(chan resolver.State, 10)}
+
+	built := make(chan resolver.Resolver)
+	go func() {
+		r, err := NewBuilder(reg).Build(resolver.Target{Endpoint: "svc"}, cc, resolver.BuildOption{})
+		if err != nil {
+			t.Error(err)
+		}
+
+		built <- r
+	}()
+
+	// the watch update arrives while the initial lookup is in flight
+	<-disc.entered
+	disc.push(key, v2)
+	cc.expect(t, "10.0.0.2:80")
+
+	// the lookup returns services older than the watch update
+	disc.mux.Lock()
+	release := disc.release
+	disc.services, disc.release = v1, nil
+	disc.mux.Unlock()
+	close(release)
+
+	r := <-built
+	cc.expectNone(t)
+
+	// resolving with no watch update in between is pushed
+	r.ResolveNow(resolver.ResolveNowOption{})
+	cc.expect(t, "10.0.0.1:80")
+
+	// closed resolvers stop receiving updates
+	r.Close()
+	disc.push(key, v2)
+	cc.expectNone(t)
+}
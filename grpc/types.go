@@ -0,0 +1,25 @@
+package grpc
+
+import "github.com/leon-gopher/discovery/registry"
+
+// Metadata is attached to resolver.Address.Metadata of each resolved address, so balancers can use
+// weight and meta of the registered service.
+type Metadata struct {
+	ID     string
+	Weight int32
+	Meta   map[string]string
+}
+
+func (md *Metadata) equal(service *registry.Service) bool {
+	if md.Weight != service.Weight || len(md.Meta) != len(service.Meta) {
+		return false
+	}
+
+	for k, v := range service.Meta {
+		if md.Meta[k] != v {
+			return false
+		}
+	}
+
+	return true
+}
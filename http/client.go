@@ -0,0 +1,66 @@
+package http
+
+import (
+	"context"
+	gohttp "net/http"
+
+	"github.com/leon-gopher/discovery"
+	"github.com/leon-gopher/discovery/balancer"
+	"github.com/leon-gopher/discovery/errors"
+)
+
+// NewClient creates a *http.Client which resolves http://<service-name>/path with discovery.
+func NewClient(cfg *Config) (*gohttp.Client, error) {
+	transport, err := NewTransport(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return &gohttp.Client{
+		Transport: transport,
+		Timeout:   transport.timeout,
+	}, nil
+}
+
+// NewTransport creates a *Transport with config given, cfg is left unchanged. The *Transport should be closed if
+// it's created with Config.ConsulAddr.
+func NewTransport(cfg *Config) (*Transport, error) {
+	if cfg == nil {
+		return nil, errors.Wrap(errors.ErrNilConfig)
+	}
+
+	c := *cfg
+	c.fillWithDefaults()
+
+	reg := c.Registry
+	if reg == nil {
+		if len(c.ConsulAddr) == 0 {
+			return nil, errors.Wrap(errors.ErrInvalidConfig)
+		}
+
+		r, err := discovery.NewRegistryWithConsul(c.ConsulAddr)
+		if err != nil {
+			return nil, err
+		}
+
+		reg = r
+	}
+
+	lb, err := balancer.NewWithName(reg, string(c.LoadBalance))
+	if err != nil {
+		if c.Registry == nil {
+			reg.Close(context.Background())
+		}
+
+		return nil, err
+	}
+
+	return &Transport{
+		registry:    reg,
+		base:        c.Transport,
+		balancer:    lb,
+		retries:     c.RetryTimes,
+		timeout:     c.Timeout,
+		ownRegistry: c.Registry == nil,
+	}, nil
+}
@@ -0,0 +1,42 @@
+package http
+
+import (
+	gohttp "net/http"
+	"time"
+
+	"github.com/leon-gopher/discovery"
+)
+
+// Config defines settings of discovery http client.
+type Config struct {
+	// ConsulAddr is used to create registry with consul when Registry is nil.
+	ConsulAddr string
+	// Registry is used to resolve services if provided.
+	Registry *discovery.Registry
+	// Timeout of http client, default to DefaultTimeout.
+	Timeout time.Duration
+	// LoadBalance algorithm, default to LBRoundRobin.
+	LoadBalance LoadBalance
+	// RetryTimes with a different service instance when dialing failed, default to DefaultRetryTimes.
+	RetryTimes int
+	// Transport is used to send requests, default to http.DefaultTransport.
+	Transport gohttp.RoundTripper
+}
+
+func (cfg *Config) fillWithDefaults() {
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = DefaultTimeout
+	}
+
+	if len(cfg.LoadBalance) == 0 {
+		cfg.LoadBalance = LBRoundRobin
+	}
+
+	if cfg.RetryTimes <= 0 {
+		cfg.RetryTimes = DefaultRetryTimes
+	}
+
+	if cfg.Transport == nil {
+		cfg.Transport = gohttp.DefaultTransport
+	}
+}
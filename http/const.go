@@ -0,0 +1,41 @@
+package http
+
+import (
+	"time"
+
+	"github.com/leon-gopher/discovery/balancer"
+)
+
+const (
+	LBRoundRobin         LoadBalance = balancer.RoundRobin
+	LBWeightedRoundRobin LoadBalance = balancer.WeightedRoundRobin
+	LBRandom             LoadBalance = balancer.WeightedRandom
+	LBP2C                LoadBalance = balancer.P2C
+	LBRingHash           LoadBalance = balancer.RingHash
+	LBBoundedRingHash    LoadBalance = balancer.BoundedRingHash
+	LBMaglev             LoadBalance = balancer.Maglev
+	LBLocality           LoadBalance = balancer.Locality
+)
+
+// LoadBalance represents name of balancer for picking service instance, see balancer.Register for customizing.
+type LoadBalance string
+
+func (lb LoadBalance) IsValid() bool {
+	return balancer.Get(string(lb)) != nil
+}
+
+const (
+	DefaultTimeout    = 5 * time.Second
+	DefaultRetryTimes = 2
+)
+
+// hints for resolving service with tags and dc, it's removed before sending to the backend.
+const (
+	HeaderTags = "X-Discovery-Tags"
+	HeaderDC   = "X-Discovery-DC"
+	// HeaderHashKey is used by hash based balancers for sticky routing.
+	HeaderHashKey = "X-Discovery-Hash-Key"
+
+	QueryTag = "discovery_tag"
+	QueryDC  = "discovery_dc"
+)
@@ -0,0 +1,177 @@
+package http
+
+import (
+	"context"
+	"net"
+	gohttp "net/http"
+	"strings"
+	"time"
+
+	"github.com/leon-gopher/discovery"
+	"github.com/leon-gopher/discovery/balancer"
+	"github.com/leon-gopher/discovery/errors"
+	"github.com/leon-gopher/discovery/registry"
+)
+
+// Transport implements http.RoundTripper which rewrites http://<service-name>/path to a service instance
+// picked from discovery.
+type Transport struct {
+	registry *discovery.Registry
+	base     gohttp.RoundTripper
+	balancer *balancer.Balancer
+	retries  int
+	timeout  time.Duration
+	// true if the registry is created by the transport, and closed with it
+	ownRegistry bool
+}
+
+func (t *Transport) RoundTrip(req *gohttp.Request) (*gohttp.Response, error) {
+	// the base transport closes the body it sent, otherwise it's closed here as required by http.RoundTripper
+	sent := false
+	defer func() {
+		if !sent && req.Body != nil {
+			req.Body.Close()
+		}
+	}()
+
+	name, tags, dc := resolveHints(req)
+	key := registry.NewServiceKey(name, tags, dc)
+	opts := []registry.DiscoveryOption{registry.WithTags(tags), registry.WithDC(dc)}
+	info := balancer.PickInfo{
+		Key: req.Header.Get(HeaderHashKey),
+	}
+
+	service, done, err := t.balancer.PickContext(req.Context(), name, info, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	tried := make(map[string]bool)
+	for i := 0; ; i++ {
+		tried[service.ID] = true
+
+		outreq, err := rewriteRequest(req, service.RemoteAddr())
+		if err != nil {
+			done(err)
+			return nil, err
+		}
+
+		resp, err := t.base.RoundTrip(outreq)
+		sent = sent || outreq.Body == req.Body
+		done(err)
+		if err == nil || !isDialError(err) || i >= t.retries {
+			return resp, err
+		}
+
+		t.registry.Logger().Warn("http.RoundTrip() dial failed", "service", key.ToString(), "addr", service.RemoteAddr(), "error", err)
+
+		// unable to retry without re-readable body
+		if req.Body != nil && req.GetBody == nil {
+			return nil, err
+		}
+
+		// retry with a different instance
+		services, lookupErr := t.registry.LookupServicesContext(req.Context(), name, opts...)
+		if lookupErr != nil {
+			return nil, err
+		}
+
+		services = excludeServices(services, tried)
+		if len(services) == 0 {
+			return nil, err
+		}
+
+		service, done, lookupErr = t.balancer.Builder().Build(services).Pick(info)
+		if lookupErr != nil {
+			return nil, err
+		}
+	}
+}
+
+// Close stops watching services picked by the transport, see balancer.Balancer.Close. The registry created with
+// Config.ConsulAddr is closed as well, while Config.Registry is left to the caller.
+func (t *Transport) Close(ctx context.Context) error {
+	t.balancer.Close()
+
+	if t.ownRegistry {
+		return t.registry.Close(ctx)
+	}
+
+	return nil
+}
+
+// resolveHints resolves service name, tags and dc of request, and removes hints of the request.
+func resolveHints(req *gohttp.Request) (name string, tags []string, dc string) {
+	name = req.URL.Hostname()
+
+	if value := req.Header.Get(HeaderTags); len(value) > 0 {
+		tags = strings.Split(value, ",")
+	}
+	dc = req.Header.Get(HeaderDC)
+
+	query := req.URL.Query()
+	if values, ok := query[QueryTag]; ok {
+		tags = append(tags, values...)
+	}
+	if value := query.Get(QueryDC); len(value) > 0 {
+		dc = value
+	}
+
+	return
+}
+
+func rewriteRequest(req *gohttp.Request, addr string) (*gohttp.Request, error) {
+	outreq := req.Clone(req.Context())
+	if outreq.Host == "" {
+		outreq.Host = req.URL.Host
+	}
+	outreq.URL.Host = addr
+
+	outreq.Header.Del(HeaderTags)
+	outreq.Header.Del(HeaderDC)
+	outreq.Header.Del(HeaderHashKey)
+
+	query := outreq.URL.Query()
+	_, hasTag := query[QueryTag]
+	_, hasDC := query[QueryDC]
+	if hasTag || hasDC {
+		query.Del(QueryTag)
+		query.Del(QueryDC)
+		outreq.URL.RawQuery = query.Encode()
+	}
+
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, errors.Wrap(err)
+		}
+
+		outreq.Body = body
+	}
+
+	return outreq, nil
+}
+
+func excludeServices(services []*registry.Service, tried map[string]bool) []*registry.Service {
+	if len(tried) == 0 {
+		return services
+	}
+
+	alive := make([]*registry.Service, 0, len(services))
+	for _, service := range services {
+		if !tried[service.ID] {
+			alive = append(alive, service)
+		}
+	}
+
+	return alive
+}
+
+func isDialError(err error) bool {
+	var opErr *net.OpError
+	if errors.As(err, &opErr) {
+		return opErr.Op == "dial"
+	}
+
+	return false
+}
@@ -0,0 +1,215 @@
+package http
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"net"
+	gohttp "net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/leon-gopher/discovery"
+	"github.com/leon-gopher/discovery/logger"
+	"github.com/leon-gopher/discovery/registry"
+)
+
+type staticDiscovery map[string][]*registry.Service
+
+func (d staticDiscovery) GetServices(name string, _ ...registry.DiscoveryOption) ([]*registry.Service, error) {
+	return d[name], nil
+}
+
+func (d staticDiscovery) Notify(registry.Event) {}
+
+func (d staticDiscovery) Watch(registry.Watcher) {}
+
+type trackedBody struct {
+	*strings.Reader
+	closed bool
+}
+
+func (b *trackedBody) Close() error {
+	b.closed = true
+	return nil
+}
+
+// newBackend starts a server replying its name and the host requested.
+func newBackend(name string) (*httptest.Server, *registry.Service) {
+	srv := httptest.NewServer(gohttp.HandlerFunc(func(w gohttp.ResponseWriter, r *gohttp.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+
+		w.Write([]byte(name + " " + r.Host + " " + r.URL.RawQuery + " " + r.Header.Get(HeaderTags) + string(body)))
+	}))
+
+	host, port, _ := net.SplitHostPort(srv.Listener.Addr().String())
+	portNum, _ := strconv.Atoi(port)
+
+	return srv, &registry.Service{ID: name, Name: "backend", IP: host, Port: portNum}
+}
+
+// closedService returns a service on a port with nothing listening.
+func closedService(t *testing.T, id string) *registry.Service {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ln.Close()
+
+	return &registry.Service{ID: id, Name: "backend", IP: "127.0.0.1", Port: ln.Addr().(*net.TCPAddr).Port}
+}
+
+func newTestTransport(t *testing.T, disc staticDiscovery, lb LoadBalance) *Transport {
+	reg, err := discovery.NewRegistry(discovery.WithDiscoveries(disc), discovery.WithLogger(logger.Nop()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	transport, err := NewTransport(&Config{Registry: reg, LoadBalance: lb})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return transport
+}
+
+func TestTransportRoundTrip(t *testing.T) {
+	srv, service := newBackend("a")
+	defer srv.Close()
+
+	transport := newTestTransport(t, staticDiscovery{"backend": {service}}, LBRoundRobin)
+	defer transport.Close(context.Background())
+
+	client := &gohttp.Client{Transport: transport}
+
+	req, _ := gohttp.NewRequest(gohttp.MethodPost, "http://backend/path?discovery_dc=dc1&x=1", strings.NewReader(" body"))
+	req.Header.Set(HeaderTags, "v1")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("Do(): %v", err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := ioutil.ReadAll(resp.Body)
+	if string(body) != "a backend x=1  body" {
+		t.Fatalf("response: %q", body)
+	}
+}
+
+func TestTransportRetry(t *testing.T) {
+	srv, service := newBackend("alive")
+	defer srv.Close()
+
+	cases := []struct {
+		name     string
+		services []*registry.Service
+		body     bool
+		ok       bool
+	}{
+		{name: "alive", services: []*registry.Service{service}, ok: true},
+		{name: "retry dial failure", services: []*registry.Service{closedService(t, "dead"), service}, ok: true},
+		{name: "retry with re-readable body", services: []*registry.Service{closedService(t, "dead"), service}, body: true, ok: true},
+		{name: "all dead", services: []*registry.Service{closedService(t, "dead1"), closedService(t, "dead2")}},
+		{name: "no service"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			transport := newTestTransport(t, staticDiscovery{"backend": tc.services}, LBRingHash)
+			defer transport.Close(context.Background())
+
+			var body *trackedBody
+			req, _ := gohttp.NewRequest(gohttp.MethodGet, "http://backend/", nil)
+			if tc.body {
+				body = &trackedBody{Reader: strings.NewReader("")}
+				req.Body = body
+				req.GetBody = func() (io.ReadCloser, error) {
+					return ioutil.NopCloser(strings.NewReader("")), nil
+				}
+			}
+
+			// dead services are picked first by their hash key
+			for i := 0; i < 10; i++ {
+				req.Header.Set(HeaderHashKey, strconv.Itoa(i))
+
+				resp, err := transport.RoundTrip(req)
+				if tc.ok != (err == nil) {
+					t.Fatalf("RoundTrip(): %v", err)
+				}
+				if err == nil {
+					resp.Body.Close()
+				}
+			}
+
+			if body != nil && !body.closed {
+				t.Fatal("request body is not closed")
+			}
+		})
+	}
+}
+
+func TestTransportClosesBodyOnError(t *testing.T) {
+	transport := newTestTransport(t, staticDiscovery{}, LBRoundRobin)
+	defer transport.Close(context.Background())
+
+	body := &trackedBody{Reader: strings.NewReader("body")}
+	req, _ := gohttp.NewRequest(gohttp.MethodPost, "http://backend/", body)
+
+	if _, err := transport.RoundTrip(req); err == nil {
+		t.Fatal("RoundTrip() without services should fail")
+	}
+	if !body.closed {
+		t.Fatal("request body is not closed")
+	}
+}
+
+func TestTransportLeavesServicesUnchanged(t *testing.T) {
+	srv, service := newBackend("a")
+	defer srv.Close()
+
+	// empty IP is dialed as local host, and must not be filled in services shared with the registry
+	service.IP = ""
+
+	transport := newTestTransport(t, staticDiscovery{"backend": {service}}, LBRoundRobin)
+	defer transport.Close(context.Background())
+
+	req, _ := gohttp.NewRequest(gohttp.MethodGet, "http://backend/", nil)
+
+	resp, err := transport.RoundTrip(req)
+	if err != nil {
+		t.Fatalf("RoundTrip(): %v", err)
+	}
+	resp.Body.Close()
+
+	if service.IP != "" || service.Meta != nil {
+		t.Fatalf("service changed: %+v", service)
+	}
+}
+
+func TestNewTransportLeavesConfigUnchanged(t *testing.T) {
+	reg, err := discovery.NewRegistry(discovery.WithLogger(logger.Nop()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := &Config{Registry: reg}
+
+	transport, err := NewTransport(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cfg.Timeout != 0 || cfg.LoadBalance != "" || cfg.RetryTimes != 0 || cfg.Transport != nil {
+		t.Fatalf("config changed: %+v", cfg)
+	}
+	if transport.timeout != DefaultTimeout || transport.retries != DefaultRetryTimes || transport.ownRegistry {
+		t.Fatalf("transport: %+v", transport)
+	}
+
+	if _, err := NewTransport(&Config{}); err == nil {
+		t.Fatal("NewTransport() without registry or consul should fail")
+	}
+}
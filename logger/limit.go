@@ -0,0 +1,91 @@
+package logger
+
+import (
+	"sync"
+	"time"
+)
+
+// RateLimit returns a Structured emitting the same message at most once every interval, count of messages
+// suppressed in between is reported with the next emitted one as field "suppressed".
+func RateLimit(log Structured, interval time.Duration) Structured {
+	return &limited{
+		log:      log,
+		interval: interval,
+		state:    &limitState{messages: make(map[string]*limitEntry)},
+	}
+}
+
+type limited struct {
+	log      Structured
+	interval time.Duration
+	state    *limitState
+}
+
+type limitState struct {
+	mux      sync.Mutex
+	messages map[string]*limitEntry
+}
+
+type limitEntry struct {
+	last       time.Time
+	suppressed int
+}
+
+func (l *limited) Debug(msg string, kvs ...interface{}) {
+	if kvs, ok := l.allow(msg, kvs); ok {
+		l.log.Debug(msg, kvs...)
+	}
+}
+
+func (l *limited) Info(msg string, kvs ...interface{}) {
+	if kvs, ok := l.allow(msg, kvs); ok {
+		l.log.Info(msg, kvs...)
+	}
+}
+
+func (l *limited) Warn(msg string, kvs ...interface{}) {
+	if kvs, ok := l.allow(msg, kvs); ok {
+		l.log.Warn(msg, kvs...)
+	}
+}
+
+func (l *limited) Error(msg string, kvs ...interface{}) {
+	if kvs, ok := l.allow(msg, kvs); ok {
+		l.log.Error(msg, kvs...)
+	}
+}
+
+// With shares limits with l, so that messages are limited regardless of fields attached.
+func (l *limited) With(kvs ...interface{}) Structured {
+	return &limited{
+		log:      l.log.With(kvs...),
+		interval: l.interval,
+		state:    l.state,
+	}
+}
+
+func (l *limited) allow(msg string, kvs []interface{}) ([]interface{}, bool) {
+	now := time.Now()
+
+	l.state.mux.Lock()
+	defer l.state.mux.Unlock()
+
+	entry, ok := l.state.messages[msg]
+	if !ok {
+		entry = new(limitEntry)
+		l.state.messages[msg] = entry
+	}
+	if ok && now.Sub(entry.last) < l.interval {
+		entry.suppressed++
+		return nil, false
+	}
+
+	if entry.suppressed > 0 {
+		kvs = append(appendKVs(nil, kvs), "suppressed", entry.suppressed)
+	}
+
+	entry.last = now
+	entry.suppressed = 0
+
+	return kvs, true
+}
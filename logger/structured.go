@@ -0,0 +1,83 @@
+package logger
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Structured is a leveled logger with key/value fields, kvs are pairs of string key and value.
+type Structured interface {
+	Debug(msg string, kvs ...interface{})
+	Info(msg string, kvs ...interface{})
+	Warn(msg string, kvs ...interface{})
+	Error(msg string, kvs ...interface{})
+
+	// With returns a logger with kvs attached to every message.
+	With(kvs ...interface{}) Structured
+}
+
+// Global returns a Structured forwarding to the logger set by SetLogger, fields are appended to the message.
+func Global() Structured {
+	return global{}
+}
+
+type global struct {
+	kvs []interface{}
+}
+
+func (g global) Debug(msg string, kvs ...interface{}) {
+	Debugf("%s", g.format(msg, kvs))
+}
+
+func (g global) Info(msg string, kvs ...interface{}) {
+	Infof("%s", g.format(msg, kvs))
+}
+
+func (g global) Warn(msg string, kvs ...interface{}) {
+	Warnf("%s", g.format(msg, kvs))
+}
+
+func (g global) Error(msg string, kvs ...interface{}) {
+	Errorf("%s", g.format(msg, kvs))
+}
+
+func (g global) With(kvs ...interface{}) Structured {
+	return global{kvs: appendKVs(g.kvs, kvs)}
+}
+
+func (g global) format(msg string, kvs []interface{}) string {
+	var b strings.Builder
+	b.WriteString(msg)
+
+	kvs = appendKVs(g.kvs, kvs)
+	for i := 0; i < len(kvs); i += 2 {
+		fmt.Fprintf(&b, " %v=%v", kvs[i], kvs[i+1])
+	}
+
+	return b.String()
+}
+
+// Nop returns a Structured discarding all messages.
+func Nop() Structured {
+	return nop{}
+}
+
+type nop struct{}
+
+func (nop) Debug(string, ...interface{})     {}
+func (nop) Info(string, ...interface{})      {}
+func (nop) Warn(string, ...interface{})      {}
+func (nop) Error(string, ...interface{})     {}
+func (n nop) With(...interface{}) Structured { return n }
+
+// appendKVs copies kvs after base, a dangling key is paired with a nil value.
+func appendKVs(base, kvs []interface{}) []interface{} {
+	merged := make([]interface{}, 0, len(base)+len(kvs)+1)
+	merged = append(merged, base...)
+	merged = append(merged, kvs...)
+	if len(merged)%2 != 0 {
+		merged = append(merged, nil)
+	}
+
+	return merged
+}
@@ -0,0 +1,70 @@
+package logger
+
+import (
+	"fmt"
+
+	"github.com/golib/zerolog"
+)
+
+// Zerolog adapts zerolog.Logger to Structured.
+type Zerolog struct {
+	log zerolog.Logger
+}
+
+// NewZerolog returns a Structured writing to log, use zerolog levels to filter messages.
+func NewZerolog(log zerolog.Logger) *Zerolog {
+	return &Zerolog{
+		log: log,
+	}
+}
+
+func (z *Zerolog) Debug(msg string, kvs ...interface{}) {
+	z.emit(z.log.Debug(), msg, kvs)
+}
+
+func (z *Zerolog) Info(msg string, kvs ...interface{}) {
+	z.emit(z.log.Info(), msg, kvs)
+}
+
+func (z *Zerolog) Warn(msg string, kvs ...interface{}) {
+	z.emit(z.log.Warn(), msg, kvs)
+}
+
+func (z *Zerolog) Error(msg string, kvs ...interface{}) {
+	z.emit(z.log.Error(), msg, kvs)
+}
+
+func (z *Zerolog) With(kvs ...interface{}) Structured {
+	kvs = appendKVs(nil, kvs)
+
+	ctx := z.log.With()
+	for i := 0; i < len(kvs); i += 2 {
+		ctx = ctx.Interface(fmt.Sprint(kvs[i]), kvs[i+1])
+	}
+
+	return &Zerolog{
+		log: ctx.Logger(),
+	}
+}
+
+func (z *Zerolog) emit(e *zerolog.Event, msg string, kvs []interface{}) {
+	if e == nil {
+		return
+	}
+
+	kvs = appendKVs(nil, kvs)
+	for i := 0; i < len(kvs); i += 2 {
+		key := fmt.Sprint(kvs[i])
+
+		switch value := kvs[i+1].(type) {
+		case error:
+			e = e.AnErr(key, value)
+		case fmt.Stringer:
+			e = e.Str(key, value.String())
+		default:
+			e = e.Interface(key, value)
+		}
+	}
+
+	e.Msg(msg)
+}
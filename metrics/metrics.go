@@ -0,0 +1,48 @@
+// Package metrics defines hooks for exporting metrics of discovery, e.g. to prometheus, without depending on any
+// metrics library. Implement Metrics with collectors of your choice, or use the prometheus sub-package, and pass it with
+// discovery.WithMetrics and consul.WithMetrics.
+package metrics
+
+import (
+	"time"
+
+	"github.com/leon-gopher/discovery/registry"
+)
+
+// Metrics receives measurements of discovery, implementations must be safe for concurrent use and never block.
+type Metrics interface {
+	// ObserveLookup reports latency and result of looking up services from an adapter.
+	ObserveLookup(adapter string, key registry.ServiceKey, latency time.Duration, err error)
+	// Fallback reports services switched to be served by a fallback adapter, e.g. file dump, instead of the first one.
+	Fallback(adapter string, key registry.ServiceKey)
+	// Recover reports services switched back to be served by the first adapter after Fallback.
+	Recover(adapter string, key registry.ServiceKey)
+	// Instances reports count of instances of the service currently.
+	Instances(key registry.ServiceKey, count int)
+
+	// WatchIndex reports index changed of a consul watch.
+	WatchIndex(key registry.ServiceKey, index uint64)
+	// WatchError reports failure of a consul blocking query.
+	WatchError(key registry.ServiceKey, err error)
+	// WatchBackoff reports delay of a consul watch for frequent changes.
+	WatchBackoff(key registry.ServiceKey, delay time.Duration)
+
+	// DegradeTrip reports an update held back by passing only degrade, with current and total nodes.
+	DegradeTrip(key registry.ServiceKey, current, total int)
+
+	// DumpStore reports result of storing services to dump.
+	DumpStore(key registry.ServiceKey, err error)
+}
+
+// Noop discards all measurements, it is the default Metrics.
+type Noop struct{}
+
+func (Noop) ObserveLookup(string, registry.ServiceKey, time.Duration, error) {}
+func (Noop) Fallback(string, registry.ServiceKey)                            {}
+func (Noop) Recover(string, registry.ServiceKey)                             {}
+func (Noop) Instances(registry.ServiceKey, int)                              {}
+func (Noop) WatchIndex(registry.ServiceKey, uint64)                          {}
+func (Noop) WatchError(registry.ServiceKey, error)                           {}
+func (Noop) WatchBackoff(registry.ServiceKey, time.Duration)                 {}
+func (Noop) DegradeTrip(registry.ServiceKey, int, int)                       {}
+func (Noop) DumpStore(registry.ServiceKey, error)                            {}
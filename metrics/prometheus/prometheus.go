@@ -0,0 +1,162 @@
+// Package prometheus implements metrics.Metrics with prometheus collectors, register it to a prometheus registry and
+// pass it with discovery.WithMetrics and consul.WithMetrics.
+package prometheus
+
+import (
+	"time"
+
+	"github.com/leon-gopher/discovery/errors"
+	"github.com/leon-gopher/discovery/metrics"
+	"github.com/leon-gopher/discovery/registry"
+	prom "github.com/prometheus/client_golang/prometheus"
+)
+
+// DefaultNamespace is namespace of metrics if not given.
+const DefaultNamespace = "discovery"
+
+var _ metrics.Metrics = (*Metrics)(nil)
+
+// Metrics is a prometheus.Collector reports measurements of discovery. Services are labeled by registry.ServiceKey,
+// and errors are labeled by their errors.Kind.
+type Metrics struct {
+	lookups      *prom.HistogramVec
+	fallbacks    *prom.CounterVec
+	fallback     *prom.GaugeVec
+	instances    *prom.GaugeVec
+	watchIndex   *prom.GaugeVec
+	watchErrors  *prom.CounterVec
+	watchBackoff *prom.HistogramVec
+	degradeTrips *prom.CounterVec
+	dumpStores   *prom.CounterVec
+}
+
+// New creates Metrics with the namespace, or DefaultNamespace if it's empty.
+func New(namespace string) *Metrics {
+	if len(namespace) == 0 {
+		namespace = DefaultNamespace
+	}
+
+	return &Metrics{
+		lookups: prom.NewHistogramVec(prom.HistogramOpts{
+			Namespace: namespace,
+			Name:      "lookup_duration_seconds",
+			Help:      "Latency of looking up services from an adapter.",
+			Buckets:   prom.DefBuckets,
+		}, []string{"adapter", "service", "error"}),
+		fallbacks: prom.NewCounterVec(prom.CounterOpts{
+			Namespace: namespace,
+			Name:      "fallbacks_total",
+			Help:      "Times of services switched to be served by a fallback adapter.",
+		}, []string{"adapter", "service"}),
+		fallback: prom.NewGaugeVec(prom.GaugeOpts{
+			Namespace: namespace,
+			Name:      "served_by_fallback",
+			Help:      "Whether services are served by a fallback adapter currently, 1 for the fallback one and 0 for the first.",
+		}, []string{"service"}),
+		instances: prom.NewGaugeVec(prom.GaugeOpts{
+			Namespace: namespace,
+			Name:      "instances",
+			Help:      "Count of instances of the service currently.",
+		}, []string{"service"}),
+		watchIndex: prom.NewGaugeVec(prom.GaugeOpts{
+			Namespace: namespace,
+			Name:      "watch_index",
+			Help:      "Index of the consul watch.",
+		}, []string{"service"}),
+		watchErrors: prom.NewCounterVec(prom.CounterOpts{
+			Namespace: namespace,
+			Name:      "watch_errors_total",
+			Help:      "Failures of consul blocking queries.",
+		}, []string{"service", "error"}),
+		watchBackoff: prom.NewHistogramVec(prom.HistogramOpts{
+			Namespace: namespace,
+			Name:      "watch_backoff_seconds",
+			Help:      "Delay of consul watches for frequent changes.",
+			Buckets:   prom.DefBuckets,
+		}, []string{"service"}),
+		degradeTrips: prom.NewCounterVec(prom.CounterOpts{
+			Namespace: namespace,
+			Name:      "degrade_trips_total",
+			Help:      "Updates held back by passing only degrade.",
+		}, []string{"service"}),
+		dumpStores: prom.NewCounterVec(prom.CounterOpts{
+			Namespace: namespace,
+			Name:      "dump_stores_total",
+			Help:      "Results of storing services to dump.",
+		}, []string{"service", "error"}),
+	}
+}
+
+func (m *Metrics) collectors() []prom.Collector {
+	return []prom.Collector{
+		m.lookups,
+		m.fallbacks,
+		m.fallback,
+		m.instances,
+		m.watchIndex,
+		m.watchErrors,
+		m.watchBackoff,
+		m.degradeTrips,
+		m.dumpStores,
+	}
+}
+
+// Describe implements prometheus.Collector.
+func (m *Metrics) Describe(ch chan<- *prom.Desc) {
+	for _, c := range m.collectors() {
+		c.Describe(ch)
+	}
+}
+
+// Collect implements prometheus.Collector.
+func (m *Metrics) Collect(ch chan<- prom.Metric) {
+	for _, c := range m.collectors() {
+		c.Collect(ch)
+	}
+}
+
+func (m *Metrics) ObserveLookup(adapter string, key registry.ServiceKey, latency time.Duration, err error) {
+	m.lookups.WithLabelValues(adapter, key.ToString(), errorLabel(err)).Observe(latency.Seconds())
+}
+
+func (m *Metrics) Fallback(adapter string, key registry.ServiceKey) {
+	m.fallbacks.WithLabelValues(adapter, key.ToString()).Inc()
+	m.fallback.WithLabelValues(key.ToString()).Set(1)
+}
+
+func (m *Metrics) Recover(adapter string, key registry.ServiceKey) {
+	m.fallback.WithLabelValues(key.ToString()).Set(0)
+}
+
+func (m *Metrics) Instances(key registry.ServiceKey, count int) {
+	m.instances.WithLabelValues(key.ToString()).Set(float64(count))
+}
+
+func (m *Metrics) WatchIndex(key registry.ServiceKey, index uint64) {
+	m.watchIndex.WithLabelValues(key.ToString()).Set(float64(index))
+}
+
+func (m *Metrics) WatchError(key registry.ServiceKey, err error) {
+	m.watchErrors.WithLabelValues(key.ToString(), errorLabel(err)).Inc()
+}
+
+func (m *Metrics) WatchBackoff(key registry.ServiceKey, delay time.Duration) {
+	m.watchBackoff.WithLabelValues(key.ToString()).Observe(delay.Seconds())
+}
+
+func (m *Metrics) DegradeTrip(key registry.ServiceKey, current, total int) {
+	m.degradeTrips.WithLabelValues(key.ToString()).Inc()
+}
+
+func (m *Metrics) DumpStore(key registry.ServiceKey, err error) {
+	m.dumpStores.WithLabelValues(key.ToString(), errorLabel(err)).Inc()
+}
+
+// errorLabel returns kind of err, or empty for nil so successes are told apart from unknown errors.
+func errorLabel(err error) string {
+	if err == nil {
+		return ""
+	}
+
+	return errors.KindOf(err).String()
+}
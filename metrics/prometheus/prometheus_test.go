@@ -0,0 +1,64 @@
+package prometheus
+
+import (
+	"testing"
+	"time"
+
+	"github.com/leon-gopher/discovery/errors"
+	"github.com/leon-gopher/discovery/registry"
+	prom "github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestMetrics(t *testing.T) {
+	m := New("")
+
+	reg := prom.NewRegistry()
+	if err := reg.Register(m); err != nil {
+		t.Fatalf("Register(): %v", err)
+	}
+
+	key := registry.NewServiceKey("svc", []string{"v1"}, "dc1")
+
+	m.ObserveLookup("consul", key, time.Millisecond, errors.Wrap(errors.ErrUnavailable))
+	m.ObserveLookup("consul", key, time.Millisecond, nil)
+	m.Instances(key, 3)
+
+	m.Fallback("file", key)
+	if v := testutil.ToFloat64(m.fallback.WithLabelValues(key.ToString())); v != 1 {
+		t.Fatalf("served_by_fallback: %v after Fallback(), expected 1", v)
+	}
+
+	m.Recover("consul", key)
+	if v := testutil.ToFloat64(m.fallback.WithLabelValues(key.ToString())); v != 0 {
+		t.Fatalf("served_by_fallback: %v after Recover(), expected 0", v)
+	}
+
+	if v := testutil.ToFloat64(m.fallbacks.WithLabelValues("file", key.ToString())); v != 1 {
+		t.Fatalf("fallbacks_total: %v, expected 1", v)
+	}
+	if v := testutil.ToFloat64(m.instances.WithLabelValues(key.ToString())); v != 3 {
+		t.Fatalf("instances: %v, expected 3", v)
+	}
+
+	families, err := reg.Gather()
+	if err != nil {
+		t.Fatalf("Gather(): %v", err)
+	}
+
+	var lookups uint64
+	for _, family := range families {
+		if family.GetName() != "discovery_lookup_duration_seconds" {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			lookups += metric.GetHistogram().GetSampleCount()
+		}
+		if len(family.GetMetric()) != 2 {
+			t.Fatalf("lookups labeled %d, expected by failed and succeeded", len(family.GetMetric()))
+		}
+	}
+	if lookups != 2 {
+		t.Fatalf("lookups: %d, expected 2", lookups)
+	}
+}
@@ -1,6 +1,10 @@
 package discovery
 
 import (
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/leon-gopher/discovery/logger"
+	"github.com/leon-gopher/discovery/metrics"
 	"github.com/leon-gopher/discovery/registry"
 )
 
@@ -11,6 +15,14 @@ type registryOption struct {
 	discoveries  []registry.Discovery
 	bootstrap    map[registry.ServiceKey]int
 	failType     FailType
+
+	deregisterOnClose bool
+	watcherQueueSize  int
+
+	metrics        metrics.Metrics
+	tracerProvider trace.TracerProvider
+	hooks          *registry.Hooks
+	logger         logger.Structured
 }
 
 func WithFailType(t FailType) RegistryOption {
@@ -19,6 +31,57 @@ func WithFailType(t FailType) RegistryOption {
 	}
 }
 
+// WithDeregisterOnClose deregisters services registered through the Registry when closing.
+func WithDeregisterOnClose(deregister bool) RegistryOption {
+	return func(o *registryOption) {
+		o.deregisterOnClose = deregister
+	}
+}
+
+// WithWatcherQueueSize sets max pending service keys of each watcher, updates of a pending key are coalesced with the latest.
+func WithWatcherQueueSize(size int) RegistryOption {
+	return func(o *registryOption) {
+		if size > 0 {
+			o.watcherQueueSize = size
+		}
+	}
+}
+
+// WithMetrics reports lookups, fallbacks and instances of services to m, see consul.WithMetrics for watches and dumps.
+func WithMetrics(m metrics.Metrics) RegistryOption {
+	return func(o *registryOption) {
+		if m != nil {
+			o.metrics = m
+		}
+	}
+}
+
+// WithTracerProvider creates spans of lookups and registrations with tp, default to the global one of otel.
+func WithTracerProvider(tp trace.TracerProvider) RegistryOption {
+	return func(o *registryOption) {
+		if tp != nil {
+			o.tracerProvider = tp
+		}
+	}
+}
+
+// WithHooks calls back on degrade, recover and fallback of lookups, see consul.WithHooks for watches, dumps and
+// registrations.
+func WithHooks(hooks *registry.Hooks) RegistryOption {
+	return func(o *registryOption) {
+		o.hooks = hooks
+	}
+}
+
+// WithLogger writes logs of the Registry to l instead of the global logger, see consul.WithLogger for the adapter.
+func WithLogger(l logger.Structured) RegistryOption {
+	return func(o *registryOption) {
+		if l != nil {
+			o.logger = l
+		}
+	}
+}
+
 func WithRegisters(regs ...registry.Registrator) RegistryOption {
 	return func(o *registryOption) {
 		o.registrators = append(o.registrators, regs...)
@@ -0,0 +1,137 @@
+package discovery
+
+import (
+	"time"
+
+	"github.com/leon-gopher/discovery/errors"
+	"github.com/leon-gopher/discovery/logger"
+	"github.com/leon-gopher/discovery/registry"
+)
+
+// withReadiness adds a critical TTL check for readiness to opts if registry.WithReadiness or registry.WithReadinessFunc
+// given, and returns check id and ready channel of it.
+func withReadiness(log logger.Structured, service *registry.Service, opts []registry.RegistratorOption) ([]registry.RegistratorOption, string, <-chan struct{}) {
+	o := registry.NewCommonRegistratorOption(opts...)
+
+	ready := o.Readiness
+	if ready == nil && o.ReadinessFunc != nil {
+		readyC := make(chan struct{})
+		go func(fn func() error) {
+			if err := fn(); err != nil {
+				log.Error("Registry readiness failed", "service", service.ServiceID(), "error", err)
+				return
+			}
+
+			close(readyC)
+		}(o.ReadinessFunc)
+
+		ready = readyC
+	}
+	if ready == nil {
+		return opts, "", nil
+	}
+
+	checkID := "service:" + service.ServiceID() + registry.ReadinessCheckSuffix
+
+	opts = append(opts[:len(opts):len(opts)], registry.WithTTLHealthCheck(&registry.TTLHealthCheck{
+		ID:     checkID,
+		Name:   "readiness",
+		TTL:    DefaultReadinessTTL,
+		Status: registry.HealthCritical,
+	}))
+
+	return opts, checkID, ready
+}
+
+// startReadiness marks the readiness check passing once ready closed, and keeps it passing until unready.
+func (sr *ServiceRegistrator) startReadiness(checkID string, ready <-chan struct{}) {
+	stopC := make(chan struct{})
+	doneC := make(chan struct{})
+
+	sr.readinessMux.Lock()
+	sr.readinessCheck = checkID
+	sr.readinessStop = stopC
+	sr.readinessDone = doneC
+	sr.readinessMux.Unlock()
+
+	go func() {
+		defer close(doneC)
+
+		select {
+		case <-ready:
+		case <-stopC:
+			return
+		}
+
+		sr.log().Info("ServiceRegistrator ready", "service", sr.Service().ServiceID())
+
+		ticker := time.NewTicker(DefaultReadinessTTL / 3)
+		defer ticker.Stop()
+
+		for {
+			err := sr.updateCheck(checkID, registry.HealthPassing, "ready")
+			if err != nil {
+				sr.log().Error("ServiceRegistrator.Readiness() failed", "service", sr.Service().ServiceID(), "error", err)
+			}
+
+			select {
+			case <-ticker.C:
+			case <-stopC:
+				return
+			}
+		}
+	}()
+}
+
+// stopReadiness stops refreshing the readiness check and waits for the update in flight, the check expires after
+// DefaultReadinessTTL. It returns id of the check for the first call only, so draining and deregistering fail the check once.
+func (sr *ServiceRegistrator) stopReadiness() string {
+	sr.readinessMux.Lock()
+	if sr.readinessStop != nil {
+		close(sr.readinessStop)
+		sr.readinessStop = nil
+	}
+	doneC := sr.readinessDone
+	sr.readinessDone = nil
+
+	checkID := sr.readinessCheck
+	sr.readinessCheck = ""
+	sr.readinessMux.Unlock()
+
+	if doneC != nil {
+		<-doneC
+	}
+
+	return checkID
+}
+
+// unready marks the readiness check critical before shutting down, so consumers stop routing immediately.
+func (sr *ServiceRegistrator) unready(note string) {
+	checkID := sr.stopReadiness()
+	if len(checkID) == 0 {
+		return
+	}
+
+	err := sr.updateCheck(checkID, registry.HealthCritical, note)
+	if err != nil {
+		sr.log().Error("ServiceRegistrator.Readiness() failed", "service", sr.Service().ServiceID(), "error", err)
+	}
+}
+
+func (sr *ServiceRegistrator) updateCheck(checkID string, status registry.HealthStatus, note string) (err error) {
+	err = errors.Wrap(errors.ErrNotSupported)
+
+	for _, register := range sr.registrators {
+		updater, ok := register.(registry.CheckUpdater)
+		if !ok {
+			continue
+		}
+
+		err = updater.UpdateCheck(sr.Service(), checkID, status, note)
+		if err != nil {
+			err = errors.Errorf("%T.UpdateCheck(%s, %s): %+v", register, checkID, status, err)
+		}
+	}
+
+	return
+}
@@ -0,0 +1,114 @@
+package discovery
+
+import (
+	"context"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/leon-gopher/discovery/errors"
+	"github.com/leon-gopher/discovery/logger"
+	"github.com/leon-gopher/discovery/registry"
+)
+
+// waitHistory waits until history of rr contains call.
+func waitHistory(t *testing.T, rr *recordingRegistrator, call string) {
+	t.Helper()
+
+	deadline := time.Now().Add(time.Second)
+	for !strings.Contains(rr.history(), call) {
+		if time.Now().After(deadline) {
+			t.Fatalf("history: %s, expected %s", rr.history(), call)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestReadiness(t *testing.T) {
+	ready := make(chan struct{})
+	close(ready)
+
+	cases := []struct {
+		name  string
+		opt   registry.RegisterOpt
+		ready bool
+	}{
+		{name: "never ready", opt: registry.WithReadiness(make(chan struct{}))},
+		{name: "ready", opt: registry.WithReadiness(ready), ready: true},
+		{name: "ready func", opt: registry.WithReadinessFunc(func() error { return nil }), ready: true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			rr := new(recordingRegistrator)
+
+			r, err := NewRegistry(WithRegisters(rr), WithLogger(logger.Nop()))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if _, err := r.Register(&registry.Service{ID: "svc-1", Name: "svc", IP: "10.0.0.1", Port: 80}, tc.opt); err != nil {
+				t.Fatal(err)
+			}
+
+			if tc.ready {
+				waitHistory(t, rr, "check:passing")
+			}
+
+			if err := r.Close(context.Background()); err != nil {
+				t.Fatalf("Close(): %v", err)
+			}
+
+			// failed once by closing, and never passing again
+			history := rr.history()
+			if !strings.HasSuffix(history, ",check:critical") || strings.Count(history, "check:critical") != 1 {
+				t.Fatalf("history: %s", history)
+			}
+
+			time.Sleep(20 * time.Millisecond)
+			if rr.history() != history {
+				t.Fatalf("history: %s, changed after Close() %s", rr.history(), history)
+			}
+		})
+	}
+}
+
+func TestHeartbeat(t *testing.T) {
+	rr := new(recordingRegistrator)
+
+	r, err := NewRegistry(WithRegisters(rr), WithLogger(logger.Nop()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sr, err := r.Register(&registry.Service{ID: "svc-1", Name: "svc", IP: "10.0.0.1", Port: 80})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var healthy atomic.Value
+	healthy.Store(true)
+
+	sr.Heartbeat(5*time.Millisecond, func() error {
+		if healthy.Load().(bool) {
+			return nil
+		}
+		return errors.New("unhealthy")
+	})
+
+	waitHistory(t, rr, "ttl:passing")
+	healthy.Store(false)
+	waitHistory(t, rr, "ttl:critical")
+
+	if err := r.Close(context.Background()); err != nil {
+		t.Fatalf("Close(): %v", err)
+	}
+
+	// stopped by closing
+	history := rr.history()
+	time.Sleep(20 * time.Millisecond)
+	if rr.history() != history {
+		t.Fatalf("history: %s, changed after Close() %s", rr.history(), history)
+	}
+}
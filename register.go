@@ -1,24 +1,244 @@
 package discovery
 
 import (
+	"context"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+
 	"github.com/leon-gopher/discovery/errors"
+	"github.com/leon-gopher/discovery/logger"
 	"github.com/leon-gopher/discovery/registry"
 )
 
 type ServiceRegister interface {
 	Deregister() error
+
+	// DeregisterContext is Deregister bounded by ctx.
+	DeregisterContext(ctx context.Context) error
+
+	// Drain removes traffic gracefully before deregistering, see DrainOption.
+	Drain(ctx context.Context, opts ...DrainOption) error
+
+	// Pass, Warn and Fail report status of TTL health checks.
+	Pass(note string) error
+	Warn(note string) error
+	Fail(note string) error
+
+	// Update applies fn to a copy of the service and re-registers it with the same id and checks.
+	Update(fn func(*registry.Service)) error
+
+	// Heartbeat reports result of health to TTL health checks every interval until deregistered, nil error
+	// is reported as passing, otherwise critical with the error as note. Non-positive interval defaults to
+	// DefaultHeartbeatInterval.
+	Heartbeat(interval time.Duration, health func() error)
 }
 
 type ServiceRegistrator struct {
-	service      *registry.Service
+	registry     *Registry
 	registrators []registry.Registrator
+
+	serviceMux sync.RWMutex
+	service    *registry.Service
+
+	heartbeatMux  sync.Mutex
+	heartbeatStop chan struct{}
+	heartbeatDone chan struct{}
+
+	readinessMux   sync.Mutex
+	readinessCheck string
+	readinessStop  chan struct{}
+	readinessDone  chan struct{}
+}
+
+func (sr *ServiceRegistrator) Deregister() error {
+	return sr.DeregisterContext(context.Background())
+}
+
+func (sr *ServiceRegistrator) DeregisterContext(ctx context.Context) (err error) {
+	if sr.registry != nil {
+		var span trace.Span
+
+		ctx, span = sr.registry.tracer().Start(ctx, "discovery.Deregister", trace.WithAttributes(AttrServiceName.String(sr.Service().Name)))
+		defer func() {
+			endSpan(span, err)
+		}()
+	}
+
+	sr.stopHeartbeat()
+	sr.unready("deregistering")
+
+	if sr.registry != nil {
+		sr.registry.untrack(sr)
+	}
+
+	for _, register := range sr.registrators {
+		if cregister, ok := register.(registry.ContextRegistrator); ok {
+			err = cregister.DeregisterContext(ctx, sr.Service())
+		} else {
+			err = register.Deregister(sr.Service())
+		}
+		if err != nil {
+			err = errors.Errorf("%T.Deregister(%#v): %+v", register, sr.Service(), err)
+		}
+	}
+
+	return
 }
 
-func (sr *ServiceRegistrator) Deregister() (err error) {
+// Service returns the service registered currently.
+func (sr *ServiceRegistrator) Service() *registry.Service {
+	sr.serviceMux.RLock()
+	defer sr.serviceMux.RUnlock()
+
+	return sr.service
+}
+
+func (sr *ServiceRegistrator) Update(fn func(*registry.Service)) error {
+	sr.serviceMux.Lock()
+	defer sr.serviceMux.Unlock()
+
+	service := sr.service.Clone()
+	fn(service)
+
+	// id and name are immutable for a live registration
+	service.ID = sr.service.ID
+	service.Name = sr.service.Name
+
+	updated := false
 	for _, register := range sr.registrators {
-		err = register.Deregister(sr.service)
+		updater, ok := register.(registry.Updater)
+		if !ok {
+			continue
+		}
+
+		err := updater.Update(service)
+		if err != nil {
+			return errors.Errorf("%T.Update(%s): %+v", register, service.ServiceID(), err)
+		}
+
+		updated = true
+	}
+	if !updated {
+		return errors.Wrap(errors.ErrNotSupported)
+	}
+
+	sr.service = service
+	return nil
+}
+
+// Checks reports status of health checks of the service from registrators implemented registry.CheckReporter.
+func (sr *ServiceRegistrator) Checks(ctx context.Context) ([]*registry.CheckStatus, error) {
+	var checks []*registry.CheckStatus
+	for _, register := range sr.registrators {
+		reporter, ok := register.(registry.CheckReporter)
+		if !ok {
+			continue
+		}
+
+		statuses, err := reporter.Checks(ctx, sr.Service())
+		if err != nil {
+			return checks, errors.Errorf("%T.Checks(%s): %+v", register, sr.Service().ServiceID(), err)
+		}
+
+		checks = append(checks, statuses...)
+	}
+
+	return checks, nil
+}
+
+func (sr *ServiceRegistrator) Pass(note string) error {
+	return sr.updateTTL(registry.HealthPassing, note)
+}
+
+func (sr *ServiceRegistrator) Warn(note string) error {
+	return sr.updateTTL(registry.HealthWarning, note)
+}
+
+func (sr *ServiceRegistrator) Fail(note string) error {
+	return sr.updateTTL(registry.HealthCritical, note)
+}
+
+func (sr *ServiceRegistrator) Heartbeat(interval time.Duration, health func() error) {
+	if interval <= 0 {
+		interval = DefaultHeartbeatInterval
+	}
+
+	// replaces the running one
+	sr.stopHeartbeat()
+
+	stopC := make(chan struct{})
+	doneC := make(chan struct{})
+
+	sr.heartbeatMux.Lock()
+	sr.heartbeatStop = stopC
+	sr.heartbeatDone = doneC
+	sr.heartbeatMux.Unlock()
+
+	go func() {
+		defer close(doneC)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			var err error
+			if herr := health(); herr != nil {
+				err = sr.Fail(herr.Error())
+			} else {
+				err = sr.Pass("")
+			}
+			if err != nil {
+				sr.log().Error("ServiceRegistrator.Heartbeat() failed", "service", sr.Service().ServiceID(), "error", err)
+			}
+
+			select {
+			case <-ticker.C:
+			case <-stopC:
+				return
+			}
+		}
+	}()
+}
+
+// log returns logger of the Registry, or the global one if registered without a Registry.
+func (sr *ServiceRegistrator) log() logger.Structured {
+	if sr.registry == nil {
+		return logger.Global()
+	}
+
+	return sr.registry.opts.logger
+}
+
+// stopHeartbeat stops heartbeat and waits for the report in flight, so no status is reported after it returned.
+func (sr *ServiceRegistrator) stopHeartbeat() {
+	sr.heartbeatMux.Lock()
+	if sr.heartbeatStop != nil {
+		close(sr.heartbeatStop)
+		sr.heartbeatStop = nil
+	}
+	doneC := sr.heartbeatDone
+	sr.heartbeatDone = nil
+	sr.heartbeatMux.Unlock()
+
+	if doneC != nil {
+		<-doneC
+	}
+}
+
+func (sr *ServiceRegistrator) updateTTL(status registry.HealthStatus, note string) (err error) {
+	err = errors.Wrap(errors.ErrNotSupported)
+
+	for _, register := range sr.registrators {
+		updater, ok := register.(registry.TTLUpdater)
+		if !ok {
+			continue
+		}
+
+		err = updater.UpdateTTL(sr.Service(), status, note)
 		if err != nil {
-			err = errors.Errorf("%T.Deregister(%#v): %+v", register, sr.service, err)
+			err = errors.Errorf("%T.UpdateTTL(%s, %s): %+v", register, sr.Service().ServiceID(), status, err)
 		}
 	}
 
@@ -1,6 +1,8 @@
 package discovery
 
 import (
+	"context"
+	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
@@ -9,11 +11,15 @@ import (
 	"math/rand"
 	"time"
 
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+
 	"github.com/leon-gopher/discovery/consul"
 	"github.com/leon-gopher/discovery/dumper"
 	"github.com/leon-gopher/discovery/errors"
 	"github.com/leon-gopher/discovery/file"
 	"github.com/leon-gopher/discovery/logger"
+	"github.com/leon-gopher/discovery/metrics"
 	"github.com/leon-gopher/discovery/registry"
 )
 
@@ -23,25 +29,51 @@ func init() {
 
 // Registry wraps both register and discovery interfaces.
 type Registry struct {
-	lock     sync.Mutex
-	opts     *registryOption
-	watchers []registry.Watcher
+	lock      sync.Mutex
+	opts      *registryOption
+	watchers  []*watcherQueue
+	watcherID uint64
+
+	registeredMux sync.Mutex
+	registered    []*ServiceRegistrator
+
+	// service key => index of discovery serving it currently
+	served    sync.Map
+	servedMux sync.Mutex
+	// service key => true if degraded by the first discovery
+	degradedMux sync.Mutex
+	degraded    map[registry.ServiceKey]bool
+
+	// closed by Close to end subscriptions
+	closeC    chan struct{}
+	closeOnce sync.Once
+	subs      sync.WaitGroup
 }
 
 // NewRegistry creates a new *Registry with given register or resolver implementation.
 func NewRegistry(opts ...RegistryOption) (*Registry, error) {
-	o := new(registryOption)
+	o := &registryOption{
+		watcherQueueSize: DefaultWatcherQueueSize,
+		metrics:          metrics.Noop{},
+		tracerProvider:   otel.GetTracerProvider(),
+		logger:           logger.Global(),
+	}
 	for _, opt := range opts {
 		opt(o)
 	}
 
 	r := &Registry{
-		opts: o,
+		opts:   o,
+		closeC: make(chan struct{}),
 	}
 
 	// apply watchers
-	for _, adapter := range r.opts.discoveries {
-		adapter.Watch(registry.WatchFunc(r.watchServices))
+	for i, adapter := range r.opts.discoveries {
+		i := i
+
+		adapter.Watch(registry.WatchFunc(func(key registry.ServiceKey, services []*registry.Service) {
+			r.watchServices(i, key, services)
+		}))
 	}
 
 	return r, nil
@@ -84,49 +116,125 @@ func NewRegistryWithConsulAndFile(consulAddr, localDir string, opts ...consul.Co
 // LookupServices tries to resolve services of the name from registered discovery. It will retries among all discoveries
 // when the result is unexpected.
 func (r *Registry) LookupServices(name string, opts ...registry.DiscoveryOption) ([]*registry.Service, error) {
+	return r.LookupServicesContext(context.Background(), name, opts...)
+}
+
+// LookupServicesContext is LookupServices bounded by ctx, it stops retrying among discoveries once ctx done.
+func (r *Registry) LookupServicesContext(ctx context.Context, name string, opts ...registry.DiscoveryOption) (services []*registry.Service, err error) {
 	o := registry.NewCommonDiscoveryOption(opts...)
 	key := registry.NewServiceKey(name, o.Tags, o.DC)
 
 	var currentServices []*registry.Service
+	var currentIndex int
 	var currentErr error
-	for _, disc := range r.opts.discoveries {
-		newServices, err := disc.GetServices(name, opts...)
+
+	ctx, span := r.tracer().Start(ctx, "discovery.LookupServices", trace.WithAttributes(keyAttributes(key)...))
+	defer func() {
+		span.SetAttributes(AttrInstances.Int(len(services)))
+		if len(services) > 0 {
+			span.SetAttributes(
+				AttrAdapter.String(adapterName(r.opts.discoveries[currentIndex])),
+				AttrFallback.Bool(currentIndex > 0),
+			)
+		}
+
+		endSpan(span, err)
+	}()
+
+	for i, disc := range r.opts.discoveries {
+		if err := ctx.Err(); err != nil {
+			return nil, errors.Wrap(registry.NewError("LookupServices", "registry", key, err))
+		}
+
+		newServices, err := r.getServices(ctx, disc, key, name, opts...)
 		currentErr = err
 
 		if len(newServices) > len(currentServices) {
 			currentServices = newServices
+			currentIndex = i
 		}
 
 		if err != nil {
-			logger.Errorf("%T.LookupServices(%s, %v): %+v", disc, name, opts, err)
-			if r.opts.failType == FailFast {
+			r.opts.logger.Error("Registry.LookupServices() failed", "service", key.ToString(), "adapter", adapterName(disc), "error", err)
+			if r.opts.failType == FailFast || !r.isFallback(key, newServices, err) {
 				return nil, errors.Wrap(err)
 			}
+			if i == 0 {
+				r.setDegraded(key, disc, true, len(newServices))
+			}
 			continue
 		}
 
 		if r.isFallback(key, newServices, err) {
 			disc.Notify(registry.EventDegrade)
+			if i == 0 {
+				r.setDegraded(key, disc, true, len(newServices))
+			}
 			continue
 		}
 
 		disc.Notify(registry.EventRecover)
+		if i == 0 {
+			r.setDegraded(key, disc, false, len(newServices))
+		}
+		r.serve(key, currentIndex, currentServices)
 		return currentServices, nil
 	}
 	if len(currentServices) > 0 {
+		r.serve(key, currentIndex, currentServices)
 		return currentServices, nil
 	}
 
 	return currentServices, currentErr
 }
 
+// getServices resolves services from the discovery with a child span.
+func (r *Registry) getServices(ctx context.Context, disc registry.Discovery, key registry.ServiceKey, name string, opts ...registry.DiscoveryOption) (services []*registry.Service, err error) {
+	adapter := adapterName(disc)
+
+	ctx, span := r.tracer().Start(ctx, "discovery.GetServices", trace.WithAttributes(AttrAdapter.String(adapter)))
+	defer func() {
+		span.SetAttributes(AttrInstances.Int(len(services)))
+		endSpan(span, err)
+	}()
+
+	start := time.Now()
+	if cdisc, ok := disc.(registry.ContextDiscovery); ok {
+		services, err = cdisc.GetServicesContext(ctx, name, opts...)
+	} else {
+		services, err = disc.GetServices(name, opts...)
+	}
+	r.opts.metrics.ObserveLookup(adapter, key, time.Since(start), err)
+
+	return
+}
+
 // Register tries to register service with all registrators and returns wrapped service registrator which use for deregister service
 // by one call.
+//
+// The service is registered as critical until ready if registry.WithReadiness or registry.WithReadinessFunc given, and
+// it becomes critical again before deregistering.
 func (r *Registry) Register(service *registry.Service, opts ...registry.RegistratorOption) (registrator ServiceRegister, err error) {
+	return r.RegisterContext(context.Background(), service, opts...)
+}
+
+// RegisterContext is Register bounded by ctx, requests and retries of registrators are canceled once ctx done.
+func (r *Registry) RegisterContext(ctx context.Context, service *registry.Service, opts ...registry.RegistratorOption) (registrator ServiceRegister, err error) {
+	ctx, span := r.tracer().Start(ctx, "discovery.Register", trace.WithAttributes(AttrServiceName.String(service.Name)))
+	defer func() {
+		endSpan(span, err)
+	}()
+
+	opts, readinessCheck, ready := withReadiness(r.opts.logger, service, opts)
+
 	for _, register := range r.opts.registrators {
-		err = register.Register(service, opts...)
+		if cregister, ok := register.(registry.ContextRegistrator); ok {
+			err = cregister.RegisterContext(ctx, service, opts...)
+		} else {
+			err = register.Register(service, opts...)
+		}
 		if err != nil {
-			logger.Errorf("%T.Register(%#v, %v): %+v", register, service, opts, err)
+			r.opts.logger.Error("Registry.Register() failed", "service", service.ServiceID(), "registrator", fmt.Sprintf("%T", register), "error", err)
 
 			if r.opts.failType == FailFast {
 				return nil, errors.Wrap(err)
@@ -136,21 +244,203 @@ func (r *Registry) Register(service *registry.Service, opts ...registry.Registra
 		}
 	}
 
-	return &ServiceRegistrator{
+	sr := &ServiceRegistrator{
+		registry:     r,
 		service:      service,
 		registrators: r.opts.registrators,
-	}, err
+	}
+	r.track(sr)
+
+	if ready != nil {
+		sr.startReadiness(readinessCheck, ready)
+	}
+
+	return sr, err
 }
 
-func (r *Registry) WithWatcher(w registry.Watcher) {
+// Close fails readiness checks of services registered through the Registry, deregisters them if WithDeregisterOnClose
+// enabled, and closes all discoveries and registrators implemented registry.Closer. It waits until all goroutines exited or ctx done.
+func (r *Registry) Close(ctx context.Context) error {
+	var err error
+
+	r.registeredMux.Lock()
+	registered := r.registered
+	r.registeredMux.Unlock()
+
+	for _, sr := range registered {
+		sr.stopHeartbeat()
+		sr.unready("closing")
+	}
+
+	if r.opts.deregisterOnClose {
+		for _, sr := range registered {
+			if derr := sr.DeregisterContext(ctx); derr != nil {
+				r.opts.logger.Error("Registry.Close() deregister failed", "service", sr.Service().ServiceID(), "error", derr)
+
+				err = derr
+			}
+		}
+	}
+
+	closed := make(map[registry.Closer]bool)
+	closeFunc := func(adapter interface{}) {
+		closer, ok := adapter.(registry.Closer)
+		if !ok || closed[closer] {
+			return
+		}
+		closed[closer] = true
+
+		if cerr := closer.Close(ctx); cerr != nil {
+			r.opts.logger.Error("Registry.Close() failed", "adapter", fmt.Sprintf("%T", closer), "error", cerr)
+
+			err = cerr
+		}
+	}
+
+	for _, disc := range r.opts.discoveries {
+		closeFunc(disc)
+	}
+	for _, register := range r.opts.registrators {
+		closeFunc(register)
+	}
+
+	// end subscriptions, and stop dispatching after all adapters closed
+	r.closeOnce.Do(func() {
+		close(r.closeC)
+	})
+
 	r.lock.Lock()
-	defer r.lock.Unlock()
+	watchers := r.watchers
+	r.watchers = nil
+	r.lock.Unlock()
+
+	for _, watcher := range watchers {
+		watcher.stop()
 
-	r.watchers = append(r.watchers, w)
+		if werr := watcher.wait(ctx); werr != nil {
+			return werr
+		}
+	}
+
+	subsDone := make(chan struct{})
+	go func() {
+		r.subs.Wait()
+		close(subsDone)
+	}()
+
+	select {
+	case <-subsDone:
+	case <-ctx.Done():
+		return errors.Wrap(ctx.Err())
+	}
+
+	return err
 }
 
-func (r *Registry) WithWatcherFunc(service registry.ServiceKey, w registry.Watcher) {
-	r.WithWatcher(registry.WatchFunc(func(key registry.ServiceKey, services []*registry.Service) {
+// serve records the discovery serving services of the key, it reports fallback when switched to one not the first, and
+// recover when switched back to the first.
+func (r *Registry) serve(key registry.ServiceKey, index int, services []*registry.Service) {
+	r.servedMux.Lock()
+	prev, ok := r.served.Load(key)
+	r.served.Store(key, index)
+	r.servedMux.Unlock()
+
+	if ok && prev.(int) > 0 && index == 0 {
+		r.opts.metrics.Recover(adapterName(r.opts.discoveries[0]), key)
+	}
+
+	switched := !ok || prev.(int) != index
+	if switched && index > 0 {
+		r.opts.metrics.Fallback(adapterName(r.opts.discoveries[index]), key)
+		r.opts.hooks.Fallback(&registry.FallbackEvent{
+			Key:       key,
+			From:      adapterName(r.opts.discoveries[0]),
+			To:        adapterName(r.opts.discoveries[index]),
+			Instances: len(services),
+		})
+	}
+	r.opts.metrics.Instances(key, len(services))
+}
+
+// servedByFirst reports whether services of the key are served by the first discovery currently.
+func (r *Registry) servedByFirst(key registry.ServiceKey) bool {
+	index, ok := r.served.Load(key)
+
+	return ok && index.(int) == 0
+}
+
+// setDegraded calls back degrade or recover hooks when degrade state of the key changed.
+func (r *Registry) setDegraded(key registry.ServiceKey, disc registry.Discovery, degraded bool, current int) {
+	r.degradedMux.Lock()
+	if r.degraded[key] == degraded {
+		r.degradedMux.Unlock()
+		return
+	}
+	if r.degraded == nil {
+		r.degraded = make(map[registry.ServiceKey]bool)
+	}
+	r.degraded[key] = degraded
+	r.degradedMux.Unlock()
+
+	event := &registry.DegradeEvent{
+		Key:     key,
+		Adapter: adapterName(disc),
+		Source:  registry.DegradeBootstrap,
+		Current: current,
+		Total:   r.opts.bootstrap[key],
+	}
+	if degraded {
+		r.opts.hooks.Degrade(event)
+	} else {
+		r.opts.hooks.Recover(event)
+	}
+}
+
+// Logger returns logger of the Registry, see WithLogger. Helpers built on the Registry, e.g. http, grpc and serve,
+// write logs to it as well.
+func (r *Registry) Logger() logger.Structured {
+	return r.opts.logger
+}
+
+// Registered returns services registered through the Registry and not deregistered yet.
+func (r *Registry) Registered() []*ServiceRegistrator {
+	r.registeredMux.Lock()
+	defer r.registeredMux.Unlock()
+
+	return append([]*ServiceRegistrator(nil), r.registered...)
+}
+
+func (r *Registry) track(sr *ServiceRegistrator) {
+	r.registeredMux.Lock()
+	r.registered = append(r.registered, sr)
+	r.registeredMux.Unlock()
+}
+
+func (r *Registry) untrack(sr *ServiceRegistrator) {
+	r.registeredMux.Lock()
+	defer r.registeredMux.Unlock()
+
+	for i, registered := range r.registered {
+		if registered == sr {
+			r.registered = append(r.registered[:i:i], r.registered[i+1:]...)
+			return
+		}
+	}
+}
+
+// WithWatcher dispatches updates of all services to w, it returns cancel to stop watching and release the dispatching
+// goroutine of w.
+func (r *Registry) WithWatcher(w registry.Watcher) (cancel func()) {
+	id := r.addWatcher(w)
+
+	return func() {
+		r.removeWatcher(id)
+	}
+}
+
+// WithWatcherFunc dispatches updates of the service to w, see WithWatcher.
+func (r *Registry) WithWatcherFunc(service registry.ServiceKey, w registry.Watcher) (cancel func()) {
+	return r.WithWatcher(registry.WatchFunc(func(key registry.ServiceKey, services []*registry.Service) {
 		if key != service {
 			return
 		}
@@ -159,31 +449,65 @@ func (r *Registry) WithWatcherFunc(service registry.ServiceKey, w registry.Watch
 	}))
 }
 
-func (r *Registry) watchServices(key registry.ServiceKey, services []*registry.Service) {
-	r.lock.Lock()
-	defer r.lock.Unlock()
-
+func (r *Registry) watchServices(index int, key registry.ServiceKey, services []*registry.Service) {
 	var err error
 
+	// resolve fallback without lock, it may call discoveries
 	if r.isFallback(key, services, nil) {
-		logger.Errorf("Registry fallback triggered, service: %s, total: %v", key.ToString(), len(services))
+		r.opts.logger.Error("Registry fallback triggered", "service", key.ToString(), "total", len(services))
 
 		services, err = r.LookupServices(key.Name, registry.WithDC(key.DC), registry.WithTags(strings.Split(key.Tags, ":")))
 		if err != nil {
-			logger.Errorf("registry.LookupServices(%s): fallback with %+v", key.ToString(), err)
+			r.opts.logger.Error("Registry fallback failed", "service", key.ToString(), "error", err)
 			return
 		}
+	} else {
+		r.serve(key, index, services)
 	}
 
-	for _, w := range r.watchers {
-		w.Watch(key, services)
+	r.lock.Lock()
+	watchers := r.watchers
+	r.lock.Unlock()
+
+	// dispatch asynchronously, a slow watcher never blocks others
+	for _, watcher := range watchers {
+		watcher.enqueue(key, services)
+	}
+}
+
+// addWatcher appends watcher with its own dispatching queue and returns its id for removing.
+func (r *Registry) addWatcher(w registry.Watcher) uint64 {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.watcherID++
+
+	watcher := newWatcherQueue(r.watcherID, w, r.opts.watcherQueueSize, r.opts.logger)
+	go watcher.loop()
+
+	r.watchers = append(r.watchers[:len(r.watchers):len(r.watchers)], watcher)
+
+	return r.watcherID
+}
+
+func (r *Registry) removeWatcher(id uint64) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	for i, watcher := range r.watchers {
+		if watcher.id == id {
+			watcher.stop()
+
+			r.watchers = append(r.watchers[:i:i], r.watchers[i+1:]...)
+			return
+		}
 	}
 }
 
 func (r *Registry) isFallback(key registry.ServiceKey, services []*registry.Service, err error) bool {
-	// fallback with not found error
-	if err != nil && errors.Is(err, errors.ErrNotFound) {
-		return true
+	// fallback with any error except canceled by the caller, kinds of errors are for logging, metrics and retrying only
+	if err != nil {
+		return errors.KindOf(err) != errors.KindCanceled
 	}
 
 	// fallback with empty service
@@ -198,3 +522,12 @@ func (r *Registry) isFallback(key registry.ServiceKey, services []*registry.Serv
 
 	return false
 }
+
+// adapterName returns name of registry.Namer, or type name of the adapter.
+func adapterName(adapter interface{}) string {
+	if namer, ok := adapter.(registry.Namer); ok {
+		return namer.Name()
+	}
+
+	return fmt.Sprintf("%T", adapter)
+}
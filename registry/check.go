@@ -3,43 +3,149 @@ package registry
 import "time"
 
 const (
-	HealthTypeHTTP = "HTTP"
-	HealthTypeTCP  = "TCP"
+	HealthTypeHTTP   = "HTTP"
+	HealthTypeTCP    = "TCP"
+	HealthTypeTTL    = "TTL"
+	HealthTypeGRPC   = "GRPC"
+	HealthTypeH2PING = "H2PING"
+	HealthTypeAlias  = "ALIAS"
+	HealthTypeDocker = "DOCKER"
+	HealthTypeScript = "SCRIPT"
 )
 
+// ReadinessCheckSuffix is suffix of readiness check id, see WithReadiness.
+const ReadinessCheckSuffix = ":readiness"
+
 type HealthStatus string
 
 const (
 	HealthPassing  HealthStatus = "passing"
+	HealthWarning  HealthStatus = "warning"
 	HealthCritical HealthStatus = "critical"
 )
 
 type HealthCheck struct {
+	//可选，TTL 检查默认为 service:<service id>:ttl
+	ID   string
 	Type string
 	Name string
-	//HTTP为URI，TCP为地址
+	//HTTP为URI，TCP/H2PING为地址，GRPC为地址[/服务名]，默认为服务地址
 	URI    string
 	Method string
 	//多久检查一次
 	Interval time.Duration
+	//单次检查超时时间
+	Timeout time.Duration
 	//check初始状态
 	Status HealthStatus
 	//HTTP 支持header
 	Header map[string][]string
+	//TTL 时间内没有上报则为 critical
+	TTL time.Duration
+	//GRPC/H2PING 是否使用 TLS
+	UseTLS bool
+	//HTTP/GRPC/H2PING 是否跳过证书校验
+	TLSSkipVerify bool
+	//ALIAS 为被关联的服务 id 及节点
+	AliasService string
+	AliasNode    string
+	//DOCKER/SCRIPT 执行的命令，DOCKER 需要容器 id
+	Args              []string
+	DockerContainerID string
+	Shell             string
+	//critical 状态持续多久后注销服务，默认为 24h
+	DeregisterCriticalServiceAfter time.Duration
 }
 
 type TCPHealthCheck struct {
 	Name     string
 	Addr     string
 	Interval time.Duration
+	Timeout  time.Duration
 	Status   HealthStatus
+
+	DeregisterCriticalServiceAfter time.Duration
 }
 
 type HTTPHealthCheck struct {
+	Name          string
+	URI           string
+	Interval      time.Duration
+	Timeout       time.Duration
+	Status        HealthStatus
+	Method        string
+	Header        map[string][]string
+	TLSSkipVerify bool
+
+	DeregisterCriticalServiceAfter time.Duration
+}
+
+type TTLHealthCheck struct {
+	ID     string
+	Name   string
+	TTL    time.Duration
+	Status HealthStatus
+
+	DeregisterCriticalServiceAfter time.Duration
+}
+
+type GRPCHealthCheck struct {
+	Name string
+	//默认为服务地址
+	Addr string
+	//grpc.health.v1 的服务名，为空则检查整个 server
+	Service       string
+	UseTLS        bool
+	TLSSkipVerify bool
+	Interval      time.Duration
+	Timeout       time.Duration
+	Status        HealthStatus
+
+	DeregisterCriticalServiceAfter time.Duration
+}
+
+type H2PingHealthCheck struct {
+	Name string
+	//默认为服务地址
+	Addr          string
+	UseTLS        bool
+	TLSSkipVerify bool
+	Interval      time.Duration
+	Timeout       time.Duration
+	Status        HealthStatus
+
+	DeregisterCriticalServiceAfter time.Duration
+}
+
+type AliasHealthCheck struct {
+	Name string
+	//被关联的服务 id
+	Service string
+	//被关联服务所在节点，默认为本节点
+	Node   string
+	Status HealthStatus
+
+	DeregisterCriticalServiceAfter time.Duration
+}
+
+type DockerHealthCheck struct {
+	Name        string
+	ContainerID string
+	Shell       string
+	Args        []string
+	Interval    time.Duration
+	Timeout     time.Duration
+	Status      HealthStatus
+
+	DeregisterCriticalServiceAfter time.Duration
+}
+
+type ScriptHealthCheck struct {
 	Name     string
-	URI      string
+	Args     []string
 	Interval time.Duration
+	Timeout  time.Duration
 	Status   HealthStatus
-	Method   string
-	Header   map[string][]string
+
+	DeregisterCriticalServiceAfter time.Duration
 }
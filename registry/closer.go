@@ -0,0 +1,8 @@
+package registry
+
+import "context"
+
+// Closer is implemented by Discovery or Registrator which holds goroutines or resources.
+type Closer interface {
+	Close(context.Context) error
+}
@@ -1,5 +1,7 @@
 package registry
 
+import "context"
+
 type Event string
 
 const (
@@ -14,6 +16,16 @@ type Discovery interface {
 	Watch(Watcher)
 }
 
+// Namer is implemented by adapters which report their names, e.g. in metrics.
+type Namer interface {
+	Name() string
+}
+
+// ContextDiscovery is implemented by Discovery which supports cancellation and deadline of requests.
+type ContextDiscovery interface {
+	GetServicesContext(context.Context, string, ...DiscoveryOption) ([]*Service, error)
+}
+
 type DiscoveryOption interface {
 	IsDiscovery()
 }
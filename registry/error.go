@@ -0,0 +1,64 @@
+package registry
+
+import (
+	"fmt"
+
+	"github.com/leon-gopher/discovery/errors"
+)
+
+// Error is returned by adapters with context of the failed operation, use errors.As to extract it, and errors.Is
+// matches sentinel error of its kind, e.g. errors.ErrNotFound.
+type Error struct {
+	Kind    errors.Kind
+	Op      string
+	Adapter string
+	Key     ServiceKey
+	// StatusCode is HTTP status returned by registry, 0 if no response.
+	StatusCode int
+	Err        error
+}
+
+// NewError creates *Error of the kind classified from err.
+func NewError(op, adapter string, key ServiceKey, err error) *Error {
+	return &Error{
+		Kind:    errors.KindOf(err),
+		Op:      op,
+		Adapter: adapter,
+		Key:     key,
+		Err:     err,
+	}
+}
+
+func (e *Error) Error() string {
+	msg := fmt.Sprintf("%s.%s(%s): %s", e.Adapter, e.Op, e.Key.ToString(), e.Kind)
+	if e.StatusCode > 0 {
+		msg += fmt.Sprintf(" (status %d)", e.StatusCode)
+	}
+	if e.Err != nil {
+		msg += ": " + e.Err.Error()
+	}
+
+	return msg
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+func (e *Error) Is(target error) bool {
+	sentinel := e.Kind.Sentinel()
+
+	return sentinel != nil && sentinel == target
+}
+
+func (e *Error) ErrorKind() errors.Kind {
+	return e.Kind
+}
+
+func (e *Error) Retryable() bool {
+	return e.Kind.Retryable()
+}
+
+func (e *Error) Temporary() bool {
+	return e.Kind.Temporary()
+}
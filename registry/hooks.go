@@ -0,0 +1,136 @@
+package registry
+
+// Hooks are callbacks of lifecycle events, nil callbacks are skipped. They are called synchronously from internal
+// goroutines, so they must be safe for concurrent use and never block.
+type Hooks struct {
+	// OnDegrade is called when services of the key become degraded, e.g. fewer than threshold, see DegradeEvent.Source.
+	OnDegrade func(*DegradeEvent)
+	// OnRecover is called when services of the key recovered from degrade.
+	OnRecover func(*DegradeEvent)
+	// OnFallback is called when services of the key switched to be served by a fallback adapter, e.g. file dump.
+	OnFallback func(*FallbackEvent)
+	// OnDumpFailure is called when storing services to dump failed.
+	OnDumpFailure func(*DumpEvent)
+	// OnRegisterRetry is called when a registration or deregistration attempt failed and will be retried.
+	OnRegisterRetry func(*RegisterEvent)
+	// OnRegistered is called when a service registered successfully.
+	OnRegistered func(*RegisterEvent)
+	// OnWatchError is called when a blocking query of watch failed.
+	OnWatchError func(*WatchErrorEvent)
+	// OnRepair is called when a registration missing or drifted from the agent is re-registered by anti-entropy.
+	OnRepair func(*RepairEvent)
+}
+
+// DegradeSource tells which mechanism degraded services, so events of the same adapter are told apart.
+type DegradeSource string
+
+const (
+	// DegradeBootstrap means lookups returned fewer services than expected by bootstrap, or failed.
+	DegradeBootstrap DegradeSource = "bootstrap"
+	// DegradePassingOnly means watch updates are held back by passing only degrade of the adapter.
+	DegradePassingOnly DegradeSource = "passing_only"
+)
+
+// DegradeEvent is payload of OnDegrade and OnRecover.
+type DegradeEvent struct {
+	Key     ServiceKey
+	Adapter string
+	Source  DegradeSource
+	// Current is count of services updated, Total is count expected.
+	Current   int
+	Total     int
+	Threshold float32
+}
+
+// FallbackEvent is payload of OnFallback.
+type FallbackEvent struct {
+	Key       ServiceKey
+	From      string
+	To        string
+	Instances int
+}
+
+// DumpEvent is payload of OnDumpFailure.
+type DumpEvent struct {
+	Key       ServiceKey
+	Instances int
+	Err       error
+}
+
+// RegisterEvent is payload of OnRegisterRetry and OnRegistered.
+type RegisterEvent struct {
+	ServiceID  string
+	Name       string
+	Adapter    string
+	Deregister bool
+	// Attempt starts from 1.
+	Attempt int
+	Err     error
+}
+
+// WatchErrorEvent is payload of OnWatchError.
+type WatchErrorEvent struct {
+	Key       ServiceKey
+	Adapter   string
+	LastIndex uint64
+	Err       error
+}
+
+// RepairEvent is payload of OnRepair.
+type RepairEvent struct {
+	ServiceID string
+	Name      string
+	Adapter   string
+	Reason    string
+	// Detail of the drift, e.g. tags, meta or checks
+	Detail string
+	Err    error
+}
+
+func (h *Hooks) Degrade(e *DegradeEvent) {
+	if h != nil && h.OnDegrade != nil {
+		h.OnDegrade(e)
+	}
+}
+
+func (h *Hooks) Recover(e *DegradeEvent) {
+	if h != nil && h.OnRecover != nil {
+		h.OnRecover(e)
+	}
+}
+
+func (h *Hooks) Fallback(e *FallbackEvent) {
+	if h != nil && h.OnFallback != nil {
+		h.OnFallback(e)
+	}
+}
+
+func (h *Hooks) DumpFailure(e *DumpEvent) {
+	if h != nil && h.OnDumpFailure != nil {
+		h.OnDumpFailure(e)
+	}
+}
+
+func (h *Hooks) RegisterRetry(e *RegisterEvent) {
+	if h != nil && h.OnRegisterRetry != nil {
+		h.OnRegisterRetry(e)
+	}
+}
+
+func (h *Hooks) Registered(e *RegisterEvent) {
+	if h != nil && h.OnRegistered != nil {
+		h.OnRegistered(e)
+	}
+}
+
+func (h *Hooks) WatchError(e *WatchErrorEvent) {
+	if h != nil && h.OnWatchError != nil {
+		h.OnWatchError(e)
+	}
+}
+
+func (h *Hooks) Repair(e *RepairEvent) {
+	if h != nil && h.OnRepair != nil {
+		h.OnRepair(e)
+	}
+}
@@ -8,6 +8,21 @@ func (RegisterOpt) IsRegister() {
 type CommonRegistratorOption struct {
 	Checks   []*HealthCheck
 	Metadata map[string]string
+
+	//注册为 critical，ready 后变为 passing
+	Readiness     <-chan struct{}
+	ReadinessFunc func() error
+}
+
+func NewCommonRegistratorOption(opts ...RegistratorOption) *CommonRegistratorOption {
+	o := new(CommonRegistratorOption)
+	for _, opt := range opts {
+		switch opt := opt.(type) {
+		case RegisterOpt:
+			opt(o)
+		}
+	}
+	return o
 }
 
 func WithHealthCheck(check *HealthCheck) RegisterOpt {
@@ -23,7 +38,10 @@ func WithTCPHealthCheck(check *TCPHealthCheck) RegisterOpt {
 			Name:     check.Name,
 			URI:      check.Addr,
 			Interval: check.Interval,
+			Timeout:  check.Timeout,
 			Status:   check.Status,
+
+			DeregisterCriticalServiceAfter: check.DeregisterCriticalServiceAfter,
 		})
 	}
 }
@@ -31,17 +49,140 @@ func WithTCPHealthCheck(check *TCPHealthCheck) RegisterOpt {
 func WithHTTPHealthCheck(check *HTTPHealthCheck) RegisterOpt {
 	return func(o *CommonRegistratorOption) {
 		o.Checks = append(o.Checks, &HealthCheck{
-			Type:     HealthTypeHTTP,
+			Type:          HealthTypeHTTP,
+			Name:          check.Name,
+			URI:           check.URI,
+			Method:        check.Method,
+			Header:        check.Header,
+			Interval:      check.Interval,
+			Timeout:       check.Timeout,
+			Status:        check.Status,
+			TLSSkipVerify: check.TLSSkipVerify,
+
+			DeregisterCriticalServiceAfter: check.DeregisterCriticalServiceAfter,
+		})
+	}
+}
+
+// WithTTLHealthCheck 需要通过 Pass/Warn/Fail 或者心跳上报状态
+func WithTTLHealthCheck(check *TTLHealthCheck) RegisterOpt {
+	return func(o *CommonRegistratorOption) {
+		o.Checks = append(o.Checks, &HealthCheck{
+			ID:     check.ID,
+			Type:   HealthTypeTTL,
+			Name:   check.Name,
+			TTL:    check.TTL,
+			Status: check.Status,
+
+			DeregisterCriticalServiceAfter: check.DeregisterCriticalServiceAfter,
+		})
+	}
+}
+
+// WithGRPCHealthCheck 使用 grpc.health.v1 协议检查
+func WithGRPCHealthCheck(check *GRPCHealthCheck) RegisterOpt {
+	return func(o *CommonRegistratorOption) {
+		uri := check.Addr
+		if len(check.Service) > 0 {
+			uri += "/" + check.Service
+		}
+
+		o.Checks = append(o.Checks, &HealthCheck{
+			Type:          HealthTypeGRPC,
+			Name:          check.Name,
+			URI:           uri,
+			UseTLS:        check.UseTLS,
+			TLSSkipVerify: check.TLSSkipVerify,
+			Interval:      check.Interval,
+			Timeout:       check.Timeout,
+			Status:        check.Status,
+
+			DeregisterCriticalServiceAfter: check.DeregisterCriticalServiceAfter,
+		})
+	}
+}
+
+// WithH2PingHealthCheck 使用 http2 ping 检查
+func WithH2PingHealthCheck(check *H2PingHealthCheck) RegisterOpt {
+	return func(o *CommonRegistratorOption) {
+		o.Checks = append(o.Checks, &HealthCheck{
+			Type:          HealthTypeH2PING,
+			Name:          check.Name,
+			URI:           check.Addr,
+			UseTLS:        check.UseTLS,
+			TLSSkipVerify: check.TLSSkipVerify,
+			Interval:      check.Interval,
+			Timeout:       check.Timeout,
+			Status:        check.Status,
+
+			DeregisterCriticalServiceAfter: check.DeregisterCriticalServiceAfter,
+		})
+	}
+}
+
+// WithAliasHealthCheck 跟随其他服务的健康状态
+func WithAliasHealthCheck(check *AliasHealthCheck) RegisterOpt {
+	return func(o *CommonRegistratorOption) {
+		o.Checks = append(o.Checks, &HealthCheck{
+			Type:         HealthTypeAlias,
+			Name:         check.Name,
+			AliasService: check.Service,
+			AliasNode:    check.Node,
+			Status:       check.Status,
+
+			DeregisterCriticalServiceAfter: check.DeregisterCriticalServiceAfter,
+		})
+	}
+}
+
+// WithDockerHealthCheck 在容器内执行命令检查
+func WithDockerHealthCheck(check *DockerHealthCheck) RegisterOpt {
+	return func(o *CommonRegistratorOption) {
+		o.Checks = append(o.Checks, &HealthCheck{
+			Type:              HealthTypeDocker,
+			Name:              check.Name,
+			DockerContainerID: check.ContainerID,
+			Shell:             check.Shell,
+			Args:              check.Args,
+			Interval:          check.Interval,
+			Timeout:           check.Timeout,
+			Status:            check.Status,
+
+			DeregisterCriticalServiceAfter: check.DeregisterCriticalServiceAfter,
+		})
+	}
+}
+
+// WithScriptHealthCheck 在 agent 上执行命令检查，需要 agent 开启 enable_local_script_checks
+func WithScriptHealthCheck(check *ScriptHealthCheck) RegisterOpt {
+	return func(o *CommonRegistratorOption) {
+		o.Checks = append(o.Checks, &HealthCheck{
+			Type:     HealthTypeScript,
 			Name:     check.Name,
-			URI:      check.URI,
-			Method:   check.Method,
-			Header:   check.Header,
+			Args:     check.Args,
 			Interval: check.Interval,
+			Timeout:  check.Timeout,
 			Status:   check.Status,
+
+			DeregisterCriticalServiceAfter: check.DeregisterCriticalServiceAfter,
 		})
 	}
 }
 
+// WithReadiness 注册为 critical 状态，ready 关闭后变为 passing
+func WithReadiness(ready <-chan struct{}) RegisterOpt {
+	return func(o *CommonRegistratorOption) {
+		o.Readiness = ready
+	}
+}
+
+// WithReadinessFunc 注册为 critical 状态，fn 返回 nil 后变为 passing
+func WithReadinessFunc(fn func() error) RegisterOpt {
+	return func(o *CommonRegistratorOption) {
+		o.ReadinessFunc = fn
+	}
+}
+
 // 注册时，特殊的metadata
 // 机器所在云环境 目前为aliyun跟tencent
 func WithCloud(cloud string) RegisterOpt {
@@ -49,7 +190,7 @@ func WithCloud(cloud string) RegisterOpt {
 		if o.Metadata == nil {
 			o.Metadata = make(map[string]string)
 		}
-		o.Metadata["cloud"] = cloud
+		o.Metadata[MetaCloud] = cloud
 	}
 }
 
@@ -59,7 +200,7 @@ func WithZone(zone string) RegisterOpt {
 		if o.Metadata == nil {
 			o.Metadata = make(map[string]string)
 		}
-		o.Metadata["zone"] = zone
+		o.Metadata[MetaZone] = zone
 	}
 }
 
@@ -69,7 +210,7 @@ func WithContainer(container string) RegisterOpt {
 		if o.Metadata == nil {
 			o.Metadata = make(map[string]string)
 		}
-		o.Metadata["container"] = container
+		o.Metadata[MetaContainer] = container
 	}
 }
 
@@ -78,7 +219,7 @@ func WithRegistry(registry string) RegisterOpt {
 		if o.Metadata == nil {
 			o.Metadata = make(map[string]string)
 		}
-		o.Metadata["registry"] = registry
+		o.Metadata[MetaRegistry] = registry
 	}
 }
 
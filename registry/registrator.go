@@ -1,5 +1,7 @@
 package registry
 
+import "context"
+
 type Registrator interface {
 	//注册服务
 	Register(*Service, ...RegistratorOption) error
@@ -7,6 +9,37 @@ type Registrator interface {
 	Deregister(*Service, ...RegistratorOption) error
 }
 
+// ContextRegistrator is implemented by Registrator which supports cancellation and deadline of requests and retries.
+type ContextRegistrator interface {
+	RegisterContext(context.Context, *Service, ...RegistratorOption) error
+	DeregisterContext(context.Context, *Service, ...RegistratorOption) error
+}
+
 type RegistratorOption interface {
 	IsRegister()
 }
+
+// TTLUpdater is implemented by Registrator which supports TTL health checks.
+type TTLUpdater interface {
+	//上报 TTL 检查状态
+	UpdateTTL(service *Service, status HealthStatus, note string) error
+}
+
+// Drainer is implemented by Registrator which supports draining traffic before deregistering.
+type Drainer interface {
+	//摘除流量，如进入维护模式
+	Drain(ctx context.Context, service *Service, reason string) error
+	//服务是否已经不在健康列表中
+	IsDrained(ctx context.Context, service *Service) (bool, error)
+}
+
+// Updater is implemented by Registrator which supports updating a live registration without deregistering.
+type Updater interface {
+	//使用相同的 id 及 check 重新注册
+	Update(*Service, ...RegistratorOption) error
+}
+
+// CheckUpdater is implemented by Registrator which supports updating a TTL check by id.
+type CheckUpdater interface {
+	UpdateCheck(service *Service, checkID string, status HealthStatus, note string) error
+}
@@ -3,6 +3,7 @@ package registry
 import (
 	"os"
 	"strconv"
+	"strings"
 	"sync"
 
 	"github.com/leon-gopher/discovery/logger"
@@ -14,6 +15,33 @@ const (
 	ServiceDefaultHostname = "default"
 )
 
+// well-known keys of service metadata
+const (
+	MetaWeight    = "weight"
+	MetaZone      = "zone"
+	MetaCloud     = "cloud"
+	MetaContainer = "container"
+	MetaRegistry  = "registry"
+)
+
+// DefaultCloud is cloud of the host unless specified, see WithCloud.
+const DefaultCloud = "aliyun"
+
+// LocalZone returns zone of the host, which is the first field of hostname split by "-", empty if unknown.
+func LocalZone() string {
+	hostname, err := os.Hostname()
+	if err != nil || len(hostname) == 0 {
+		return ""
+	}
+
+	return strings.Split(hostname, "-")[0]
+}
+
+// LocalCloud returns cloud of the host, default to DefaultCloud.
+func LocalCloud() string {
+	return DefaultCloud
+}
+
 type Service struct {
 	ID         string            `discovery:"可选,服务id"`
 	Name       string            `discovery:"必填,服务名"`
@@ -73,6 +101,12 @@ func (s *Service) Addr() string {
 	return s.IP + ":" + strconv.FormatInt(int64(s.Port), 10)
 }
 
+// RemoteAddr returns address of a discovered service as is. Unlike Addr, it never fills defaults of the local host,
+// so it's safe with services shared by discoveries.
+func (s *Service) RemoteAddr() string {
+	return s.IP + ":" + strconv.FormatInt(int64(s.Port), 10)
+}
+
 func (s *Service) ServiceID() string {
 	s.FillWithDefaults()
 
@@ -84,3 +118,28 @@ func (s *Service) ServiceIP() string {
 
 	return s.IP
 }
+
+// Clone returns a copy of the service with tags and meta copied.
+func (s *Service) Clone() *Service {
+	clone := &Service{
+		ID:         s.ID,
+		Name:       s.Name,
+		IP:         s.IP,
+		IPTemplate: s.IPTemplate,
+		Port:       s.Port,
+		Weight:     s.Weight,
+	}
+
+	if s.Tags != nil {
+		clone.Tags = append([]string(nil), s.Tags...)
+	}
+
+	if s.Meta != nil {
+		clone.Meta = make(map[string]string, len(s.Meta))
+		for k, v := range s.Meta {
+			clone.Meta[k] = v
+		}
+	}
+
+	return clone
+}
@@ -0,0 +1,56 @@
+package registry
+
+// DiffServices compares services keyed by Service.ID, and returns services added, removed and updated of next.
+func DiffServices(prev, next []*Service) (added, removed, updated []*Service) {
+	prevs := make(map[string]*Service, len(prev))
+	for _, service := range prev {
+		prevs[service.ID] = service
+	}
+
+	nexts := make(map[string]bool, len(next))
+	for _, service := range next {
+		nexts[service.ID] = true
+
+		old, ok := prevs[service.ID]
+		switch {
+		case !ok:
+			added = append(added, service)
+
+		case !old.Equal(service):
+			updated = append(updated, service)
+		}
+	}
+
+	for _, service := range prev {
+		if !nexts[service.ID] {
+			removed = append(removed, service)
+		}
+	}
+
+	return
+}
+
+// Equal returns true if both services have the same id, address, weight, tags and meta.
+func (s *Service) Equal(other *Service) bool {
+	if s.ID != other.ID || s.Name != other.Name || s.IP != other.IP || s.Port != other.Port || s.Weight != other.Weight {
+		return false
+	}
+
+	if len(s.Tags) != len(other.Tags) || len(s.Meta) != len(other.Meta) {
+		return false
+	}
+
+	for i, tag := range s.Tags {
+		if other.Tags[i] != tag {
+			return false
+		}
+	}
+
+	for k, v := range s.Meta {
+		if value, ok := other.Meta[k]; !ok || value != v {
+			return false
+		}
+	}
+
+	return true
+}
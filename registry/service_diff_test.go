@@ -0,0 +1,162 @@
+package registry
+
+import (
+	"sort"
+	"strings"
+	"testing"
+)
+
+func idsOf(services []*Service) string {
+	ids := make([]string, 0, len(services))
+	for _, service := range services {
+		ids = append(ids, service.ID)
+	}
+	sort.Strings(ids)
+
+	return strings.Join(ids, ",")
+}
+
+func TestDiffServices(t *testing.T) {
+	a := &Service{ID: "a", Name: "svc", IP: "10.0.0.1", Port: 80, Weight: 100, Tags: []string{"v1"}, Meta: map[string]string{MetaZone: "z1"}}
+	b := &Service{ID: "b", Name: "svc", IP: "10.0.0.2", Port: 80, Weight: 100}
+	c := &Service{ID: "c", Name: "svc", IP: "10.0.0.3", Port: 80, Weight: 100}
+
+	cases := []struct {
+		name    string
+		prev    []*Service
+		next    []*Service
+		added   string
+		removed string
+		updated string
+	}{
+		{
+			name: "empty",
+		},
+		{
+			name:  "initial",
+			next:  []*Service{a, b},
+			added: "a,b",
+		},
+		{
+			name:    "all removed",
+			prev:    []*Service{a, b},
+			removed: "a,b",
+		},
+		{
+			name: "reordered",
+			prev: []*Service{a, b, c},
+			next: []*Service{c, a, b},
+		},
+		{
+			name: "same values with new pointers",
+			prev: []*Service{a},
+			next: []*Service{
+				{ID: "a", Name: "svc", IP: "10.0.0.1", Port: 80, Weight: 100, Tags: []string{"v1"}, Meta: map[string]string{MetaZone: "z1"}},
+			},
+		},
+		{
+			name: "port changed",
+			prev: []*Service{a, b},
+			next: []*Service{
+				{ID: "a", Name: "svc", IP: "10.0.0.1", Port: 8080, Weight: 100, Tags: []string{"v1"}, Meta: map[string]string{MetaZone: "z1"}},
+				b,
+			},
+			updated: "a",
+		},
+		{
+			name: "weight changed",
+			prev: []*Service{b},
+			next: []*Service{
+				{ID: "b", Name: "svc", IP: "10.0.0.2", Port: 80, Weight: 10},
+			},
+			updated: "b",
+		},
+		{
+			name: "tags changed",
+			prev: []*Service{a},
+			next: []*Service{
+				{ID: "a", Name: "svc", IP: "10.0.0.1", Port: 80, Weight: 100, Tags: []string{"v2"}, Meta: map[string]string{MetaZone: "z1"}},
+			},
+			updated: "a",
+		},
+		{
+			name: "meta changed",
+			prev: []*Service{a},
+			next: []*Service{
+				{ID: "a", Name: "svc", IP: "10.0.0.1", Port: 80, Weight: 100, Tags: []string{"v1"}, Meta: map[string]string{MetaZone: "z2"}},
+			},
+			updated: "a",
+		},
+		{
+			name: "meta added",
+			prev: []*Service{a},
+			next: []*Service{
+				{ID: "a", Name: "svc", IP: "10.0.0.1", Port: 80, Weight: 100, Tags: []string{"v1"}, Meta: map[string]string{MetaZone: "z1", MetaCloud: "aliyun"}},
+			},
+			updated: "a",
+		},
+		{
+			name: "mixed",
+			prev: []*Service{a, b},
+			next: []*Service{
+				{ID: "b", Name: "svc", IP: "10.0.0.2", Port: 81, Weight: 100},
+				c,
+			},
+			added:   "c",
+			removed: "a",
+			updated: "b",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			added, removed, updated := DiffServices(tc.prev, tc.next)
+
+			if ids := idsOf(added); ids != tc.added {
+				t.Errorf("added: %q, expected %q", ids, tc.added)
+			}
+			if ids := idsOf(removed); ids != tc.removed {
+				t.Errorf("removed: %q, expected %q", ids, tc.removed)
+			}
+			if ids := idsOf(updated); ids != tc.updated {
+				t.Errorf("updated: %q, expected %q", ids, tc.updated)
+			}
+		})
+	}
+}
+
+func TestServiceEqual(t *testing.T) {
+	service := &Service{ID: "a", Name: "svc", IP: "10.0.0.1", Port: 80, Tags: []string{"x", "y"}, Meta: map[string]string{MetaZone: "z1"}}
+
+	cases := []struct {
+		name  string
+		other *Service
+		equal bool
+	}{
+		{
+			name:  "same",
+			other: &Service{ID: "a", Name: "svc", IP: "10.0.0.1", Port: 80, Tags: []string{"x", "y"}, Meta: map[string]string{MetaZone: "z1"}},
+			equal: true,
+		},
+		{
+			name:  "ip",
+			other: &Service{ID: "a", Name: "svc", IP: "10.0.0.9", Port: 80, Tags: []string{"x", "y"}, Meta: map[string]string{MetaZone: "z1"}},
+		},
+		{
+			name:  "tags order",
+			other: &Service{ID: "a", Name: "svc", IP: "10.0.0.1", Port: 80, Tags: []string{"y", "x"}, Meta: map[string]string{MetaZone: "z1"}},
+		},
+		{
+			name:  "meta missing",
+			other: &Service{ID: "a", Name: "svc", IP: "10.0.0.1", Port: 80, Tags: []string{"x", "y"}},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if equal := service.Equal(tc.other); equal != tc.equal {
+				t.Fatalf("Equal(): %v, expected %v", equal, tc.equal)
+			}
+		})
+	}
+}
@@ -29,3 +29,12 @@ func (s *ServiceList) GetServices(key ServiceKey) ([]*Service, error) {
 
 	return nil, errors.Wrap(errors.ErrNotFound)
 }
+
+// Range calls fn for each key and services sequentially, it stops if fn returns false.
+func (s *ServiceList) Range(fn func(key ServiceKey, services []*Service) bool) {
+	s.services.Range(func(key, value interface{}) bool {
+		services, _ := value.([]*Service)
+
+		return fn(key.(ServiceKey), services)
+	})
+}
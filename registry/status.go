@@ -0,0 +1,57 @@
+package registry
+
+import (
+	"context"
+	"time"
+)
+
+// Source represents where services are served from.
+type Source string
+
+const (
+	SourceUnknown       Source = "unknown"
+	SourceConsulWatch   Source = "consul-watch"
+	SourceConsulOneShot Source = "consul-oneshot"
+	SourceFileDump      Source = "file-dump"
+	SourceStatics       Source = "statics"
+)
+
+// ServiceStatus reports where services of the key come from and how fresh they are.
+type ServiceStatus struct {
+	Key       ServiceKey
+	Adapter   string
+	Source    Source
+	Instances int
+	Services  []*Service `json:",omitempty"`
+	//consul blocking query index, 0 without watch
+	LastIndex uint64
+	UpdatedAt time.Time
+
+	//passing only 降级中，更新被保留直到节点数恢复
+	Degraded   bool
+	TotalNodes int
+	Threshold  float32
+
+	//dump 文件及其最后修改时间
+	DumpFile       string
+	DumpModifiedAt time.Time
+}
+
+// CheckStatus reports status of a health check of registered service.
+type CheckStatus struct {
+	ID     string
+	Name   string
+	Type   string
+	Status HealthStatus
+	Output string
+}
+
+// CheckReporter is implemented by Registrator which reports status of health checks of registered service.
+type CheckReporter interface {
+	Checks(ctx context.Context, service *Service) ([]*CheckStatus, error)
+}
+
+// StatusReporter is implemented by Discovery which reports status of services known.
+type StatusReporter interface {
+	Status() []*ServiceStatus
+}
@@ -0,0 +1,206 @@
+package discovery
+
+import (
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/leon-gopher/discovery/errors"
+	"github.com/leon-gopher/discovery/logger"
+	"github.com/leon-gopher/discovery/metrics"
+	"github.com/leon-gopher/discovery/registry"
+)
+
+// fakeDiscovery returns services and err set, and records events notified.
+type fakeDiscovery struct {
+	name string
+
+	mux      sync.Mutex
+	services []*registry.Service
+	err      error
+	events   []registry.Event
+	watchers []registry.Watcher
+}
+
+func (fd *fakeDiscovery) Name() string {
+	return fd.name
+}
+
+func (fd *fakeDiscovery) GetServices(string, ...registry.DiscoveryOption) ([]*registry.Service, error) {
+	fd.mux.Lock()
+	defer fd.mux.Unlock()
+
+	return fd.services, fd.err
+}
+
+func (fd *fakeDiscovery) Notify(event registry.Event) {
+	fd.mux.Lock()
+	fd.events = append(fd.events, event)
+	fd.mux.Unlock()
+}
+
+func (fd *fakeDiscovery) Watch(w registry.Watcher) {
+	fd.mux.Lock()
+	fd.watchers = append(fd.watchers, w)
+	fd.mux.Unlock()
+}
+
+func (fd *fakeDiscovery) set(services []*registry.Service, err error) {
+	fd.mux.Lock()
+	fd.services, fd.err = services, err
+	fd.mux.Unlock()
+}
+
+// push sets services and dispatches them to watchers.
+func (fd *fakeDiscovery) push(key registry.ServiceKey, services []*registry.Service) {
+	fd.set(services, nil)
+
+	fd.mux.Lock()
+	watchers := fd.watchers
+	fd.mux.Unlock()
+
+	for _, w := range watchers {
+		w.Watch(key, services)
+	}
+}
+
+func TestLookupServicesFallback(t *testing.T) {
+	cases := []struct {
+		name     string
+		failType FailType
+		err      error
+		// error expected, or served by the second discovery
+		expected error
+	}{
+		{name: "unavailable", err: errors.ErrUnavailable},
+		{name: "not found", err: errors.ErrNotFound},
+		{name: "invalid argument", err: errors.ErrArgument},
+		{name: "unknown", err: errors.New("unknown")},
+		{name: "canceled", err: errors.ErrCanceled, expected: errors.ErrCanceled},
+		{name: "fail fast", failType: FailFast, err: errors.ErrUnavailable, expected: errors.ErrUnavailable},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			first := &fakeDiscovery{name: "first", err: errors.Wrap(tc.err)}
+			second := &fakeDiscovery{name: "second", services: []*registry.Service{{ID: "b"}}}
+
+			r, err := NewRegistry(WithDiscoveries(first, second), WithFailType(tc.failType), WithLogger(logger.Nop()))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			services, err := r.LookupServices("svc")
+			if tc.expected != nil {
+				if !errors.Is(err, tc.expected) {
+					t.Fatalf("LookupServices(): %v, expected %v", err, tc.expected)
+				}
+				return
+			}
+
+			if err != nil || len(services) != 1 || services[0].ID != "b" {
+				t.Fatalf("LookupServices(): %v, %v, expected served by second", services, err)
+			}
+		})
+	}
+}
+
+// fallbackMetrics records fallback and recover, e.g. "fallback:second".
+type fallbackMetrics struct {
+	metrics.Noop
+
+	mux    sync.Mutex
+	events []string
+}
+
+func (m *fallbackMetrics) Fallback(adapter string, key registry.ServiceKey) {
+	m.mux.Lock()
+	m.events = append(m.events, "fallback:"+adapter)
+	m.mux.Unlock()
+}
+
+func (m *fallbackMetrics) Recover(adapter string, key registry.ServiceKey) {
+	m.mux.Lock()
+	m.events = append(m.events, "recover:"+adapter)
+	m.mux.Unlock()
+}
+
+func (m *fallbackMetrics) history() string {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+
+	return strings.Join(m.events, ",")
+}
+
+func TestServeFallbackMetrics(t *testing.T) {
+	first := &fakeDiscovery{name: "first", services: []*registry.Service{{ID: "a"}}}
+	second := &fakeDiscovery{name: "second", services: []*registry.Service{{ID: "b"}}}
+	m := new(fallbackMetrics)
+
+	r, err := NewRegistry(WithDiscoveries(first, second), WithMetrics(m), WithLogger(logger.Nop()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	steps := []struct {
+		err     error
+		history string
+	}{
+		// served by the first, nothing to report
+		{history: ""},
+		{err: errors.ErrUnavailable, history: "fallback:second"},
+		// still served by the fallback one
+		{err: errors.ErrUnavailable, history: "fallback:second"},
+		{history: "fallback:second,recover:first"},
+		{history: "fallback:second,recover:first"},
+	}
+
+	for i, step := range steps {
+		if step.err != nil {
+			first.set(nil, errors.Wrap(step.err))
+		} else {
+			first.set([]*registry.Service{{ID: "a"}}, nil)
+		}
+
+		if _, err := r.LookupServices("svc"); err != nil {
+			t.Fatalf("step %d: LookupServices(): %v", i, err)
+		}
+		if history := m.history(); history != step.history {
+			t.Fatalf("step %d: history: %s, expected %s", i, history, step.history)
+		}
+	}
+}
+
+func TestDegradeSource(t *testing.T) {
+	first := &fakeDiscovery{name: "first", services: []*registry.Service{{ID: "a"}}}
+	second := &fakeDiscovery{name: "second", services: []*registry.Service{{ID: "a"}, {ID: "b"}}}
+
+	var events []string
+	hooks := &registry.Hooks{
+		OnDegrade: func(e *registry.DegradeEvent) {
+			events = append(events, "degrade:"+e.Adapter+":"+string(e.Source))
+		},
+		OnRecover: func(e *registry.DegradeEvent) {
+			events = append(events, "recover:"+e.Adapter+":"+string(e.Source))
+		},
+	}
+
+	r, err := NewRegistry(WithDiscoveries(first, second), WithBootstrapByName("svc", 2), WithHooks(hooks), WithLogger(logger.Nop()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// fewer than expected by bootstrap
+	if _, err := r.LookupServices("svc"); err != nil {
+		t.Fatalf("LookupServices(): %v", err)
+	}
+
+	first.set([]*registry.Service{{ID: "a"}, {ID: "b"}}, nil)
+	if _, err := r.LookupServices("svc"); err != nil {
+		t.Fatalf("LookupServices(): %v", err)
+	}
+
+	if history := strings.Join(events, ","); history != "degrade:first:bootstrap,recover:first:bootstrap" {
+		t.Fatalf("events: %s", history)
+	}
+}
@@ -0,0 +1,8 @@
+package serve
+
+import "time"
+
+const (
+	DefaultDrainPeriod     = 5 * time.Second
+	DefaultShutdownTimeout = 30 * time.Second
+)
@@ -0,0 +1,49 @@
+package serve
+
+import (
+	"context"
+	"net"
+
+	"github.com/leon-gopher/discovery"
+	"github.com/leon-gopher/discovery/registry"
+	"google.golang.org/grpc"
+)
+
+type grpcServer struct {
+	srv *grpc.Server
+}
+
+func (gs *grpcServer) serve(ln net.Listener) error {
+	err := gs.srv.Serve(ln)
+	if err == grpc.ErrServerStopped {
+		err = nil
+	}
+
+	return err
+}
+
+func (gs *grpcServer) shutdown(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		gs.srv.GracefulStop()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (gs *grpcServer) close() {
+	gs.srv.Stop()
+}
+
+// ServeGRPC listens on port of svc and registers svc with the bound port, so port of 0 is supported.
+//
+// It blocks until ctx done, then deregisters svc, waits the drain period and stops srv gracefully.
+func ServeGRPC(ctx context.Context, reg *discovery.Registry, srv *grpc.Server, svc *registry.Service, opts ...Option) error {
+	return run(ctx, reg, &grpcServer{srv: srv}, listenAddr("", svc), svc, newOption(opts...))
+}
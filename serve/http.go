@@ -0,0 +1,43 @@
+package serve
+
+import (
+	"context"
+	"net"
+	"net/http"
+
+	"github.com/leon-gopher/discovery"
+	"github.com/leon-gopher/discovery/registry"
+)
+
+type httpServer struct {
+	srv *http.Server
+}
+
+func (hs *httpServer) serve(ln net.Listener) (err error) {
+	if hs.srv.TLSConfig != nil {
+		err = hs.srv.ServeTLS(ln, "", "")
+	} else {
+		err = hs.srv.Serve(ln)
+	}
+	if err == http.ErrServerClosed {
+		err = nil
+	}
+
+	return
+}
+
+func (hs *httpServer) shutdown(ctx context.Context) error {
+	return hs.srv.Shutdown(ctx)
+}
+
+func (hs *httpServer) close() {
+	hs.srv.Close()
+}
+
+// ServeHTTP listens on srv.Addr, or port of svc if srv.Addr is empty, and registers svc with the bound port, so port
+// of 0 is supported. It serves with TLS if srv.TLSConfig is provided with certificates.
+//
+// It blocks until ctx done, then deregisters svc, waits the drain period and shuts down srv gracefully.
+func ServeHTTP(ctx context.Context, reg *discovery.Registry, srv *http.Server, svc *registry.Service, opts ...Option) error {
+	return run(ctx, reg, &httpServer{srv: srv}, listenAddr(srv.Addr, svc), svc, newOption(opts...))
+}
@@ -0,0 +1,67 @@
+package serve
+
+import (
+	"os"
+	"time"
+
+	"github.com/leon-gopher/discovery/registry"
+)
+
+type Option func(*option)
+
+type option struct {
+	network         string
+	registerOpts    []registry.RegistratorOption
+	drainPeriod     time.Duration
+	shutdownTimeout time.Duration
+	signals         []os.Signal
+}
+
+func newOption(opts ...Option) *option {
+	o := &option{
+		network:         "tcp",
+		drainPeriod:     DefaultDrainPeriod,
+		shutdownTimeout: DefaultShutdownTimeout,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	return o
+}
+
+// WithNetwork sets network of listener, default to tcp.
+func WithNetwork(network string) Option {
+	return func(o *option) {
+		o.network = network
+	}
+}
+
+// WithRegisterOptions sets options passed to Registry.Register, e.g. health checks and readiness.
+func WithRegisterOptions(opts ...registry.RegistratorOption) Option {
+	return func(o *option) {
+		o.registerOpts = append(o.registerOpts, opts...)
+	}
+}
+
+// WithDrainPeriod sets waiting between deregistering and stopping server, it gives consumers time to observe the
+// deregistration while in-flight requests are still served. Default to DefaultDrainPeriod.
+func WithDrainPeriod(d time.Duration) Option {
+	return func(o *option) {
+		o.drainPeriod = d
+	}
+}
+
+// WithShutdownTimeout sets max waiting of graceful stop, the server is closed forcibly after it.
+func WithShutdownTimeout(d time.Duration) Option {
+	return func(o *option) {
+		o.shutdownTimeout = d
+	}
+}
+
+// WithSignals shuts down the server when any of signals received, e.g. syscall.SIGTERM.
+func WithSignals(signals ...os.Signal) Option {
+	return func(o *option) {
+		o.signals = append(o.signals, signals...)
+	}
+}
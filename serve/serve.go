@@ -0,0 +1,126 @@
+// Package serve provides helpers which run servers with service registered on listen and deregistered on shutdown.
+package serve
+
+import (
+	"context"
+	"net"
+	"os"
+	"os/signal"
+	"strconv"
+	"time"
+
+	"github.com/leon-gopher/discovery"
+	"github.com/leon-gopher/discovery/errors"
+	"github.com/leon-gopher/discovery/registry"
+)
+
+// server abstracts lifecycle of http and grpc servers.
+type server interface {
+	serve(ln net.Listener) error
+	shutdown(ctx context.Context) error
+	close()
+}
+
+// run listens on addr, registers svc with the bound port, and serves until ctx done or any of signals received. Then
+// it deregisters svc, waits the drain period and stops the server gracefully.
+func run(ctx context.Context, reg *discovery.Registry, srv server, addr string, svc *registry.Service, o *option) error {
+	if reg == nil || svc == nil {
+		return errors.Wrap(errors.ErrArgument)
+	}
+
+	ln, err := net.Listen(o.network, addr)
+	if err != nil {
+		return errors.Wrap(err)
+	}
+
+	service := svc.Clone()
+	if tcpAddr, ok := ln.Addr().(*net.TCPAddr); ok {
+		service.Port = tcpAddr.Port
+	}
+
+	errC := make(chan error, 1)
+	go func() {
+		errC <- srv.serve(ln)
+	}()
+
+	sr, err := reg.RegisterContext(ctx, service, o.registerOpts...)
+	if err != nil {
+		srv.close()
+		<-errC
+
+		return errors.Wrap(err)
+	}
+
+	log := reg.Logger().With("addr", ln.Addr().String(), "service", service.ServiceID())
+	log.Info("serve.Serve() registered")
+
+	sigC := make(chan os.Signal, 1)
+	if len(o.signals) > 0 {
+		signal.Notify(sigC, o.signals...)
+		defer signal.Stop(sigC)
+	}
+
+	select {
+	case err = <-errC:
+		// server exited unexpectedly, never leave it registered
+		if derr := sr.Deregister(); derr != nil {
+			log.Error("serve.Serve() deregister failed", "error", derr)
+		}
+
+		return wrap(err)
+
+	case sig := <-sigC:
+		log.Info("serve.Serve() shutting down", "signal", sig.String())
+
+	case <-ctx.Done():
+		log.Info("serve.Serve() shutting down", "error", ctx.Err())
+	}
+
+	// 1. deregister, consumers stop picking the instance
+	if derr := sr.Deregister(); derr != nil {
+		log.Error("serve.Serve() deregister failed", "error", derr)
+	}
+
+	// 2. drain, serve in-flight and late requests until consumers observed
+	if o.drainPeriod > 0 {
+		timer := time.NewTimer(o.drainPeriod)
+		select {
+		case <-timer.C:
+		case err = <-errC:
+			timer.Stop()
+
+			return wrap(err)
+		}
+	}
+
+	// 3. stop gracefully, forcibly after shutdown timeout
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), o.shutdownTimeout)
+	defer cancel()
+
+	err = srv.shutdown(shutdownCtx)
+	if err != nil {
+		log.Error("serve.Shutdown() failed", "error", err)
+
+		srv.close()
+	}
+	<-errC
+
+	return wrap(err)
+}
+
+// listenAddr returns addr if provided, otherwise port of svc on all interfaces.
+func listenAddr(addr string, svc *registry.Service) string {
+	if len(addr) > 0 || svc == nil {
+		return addr
+	}
+
+	return ":" + strconv.Itoa(svc.Port)
+}
+
+func wrap(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	return errors.Wrap(err)
+}
@@ -0,0 +1,189 @@
+package serve
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/leon-gopher/discovery"
+	"github.com/leon-gopher/discovery/errors"
+	"github.com/leon-gopher/discovery/logger"
+	"github.com/leon-gopher/discovery/registry"
+	"google.golang.org/grpc"
+)
+
+// fakeRegistrator records the port registered, and fails registering with err if it's set.
+type fakeRegistrator struct {
+	mux          sync.Mutex
+	err          error
+	port         int
+	registered   chan struct{}
+	deregistered chan struct{}
+}
+
+func newFakeRegistrator(err error) *fakeRegistrator {
+	return &fakeRegistrator{
+		err:          err,
+		registered:   make(chan struct{}),
+		deregistered: make(chan struct{}),
+	}
+}
+
+func (fr *fakeRegistrator) Register(srv *registry.Service, opts ...registry.RegistratorOption) error {
+	if fr.err != nil {
+		return fr.err
+	}
+
+	fr.mux.Lock()
+	fr.port = srv.Port
+	fr.mux.Unlock()
+
+	close(fr.registered)
+	return nil
+}
+
+func (fr *fakeRegistrator) Deregister(srv *registry.Service, opts ...registry.RegistratorOption) error {
+	close(fr.deregistered)
+	return nil
+}
+
+func (fr *fakeRegistrator) addr() string {
+	fr.mux.Lock()
+	defer fr.mux.Unlock()
+
+	return "127.0.0.1:" + strconv.Itoa(fr.port)
+}
+
+func newRegistry(t *testing.T, fr *fakeRegistrator) *discovery.Registry {
+	r, err := discovery.NewRegistry(discovery.WithRegisters(fr), discovery.WithLogger(logger.Nop()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return r
+}
+
+func wait(t *testing.T, c <-chan struct{}, what string) {
+	t.Helper()
+
+	select {
+	case <-c:
+	case <-time.After(time.Second):
+		t.Fatalf("%s timed out", what)
+	}
+}
+
+func TestServeHTTP(t *testing.T) {
+	fr := newFakeRegistrator(nil)
+	r := newRegistry(t, fr)
+
+	srv := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})}
+	svc := &registry.Service{ID: "svc-1", Name: "svc", IP: "127.0.0.1"}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errC := make(chan error, 1)
+	go func() {
+		errC <- ServeHTTP(ctx, r, srv, svc, WithDrainPeriod(100*time.Millisecond))
+	}()
+
+	// registered with the port bound
+	wait(t, fr.registered, "register")
+	if svc.Port != 0 {
+		t.Fatalf("svc changed: %+v", svc)
+	}
+
+	resp, err := http.Get("http://" + fr.addr())
+	if err != nil {
+		t.Fatalf("Get(): %v", err)
+	}
+	resp.Body.Close()
+
+	cancel()
+
+	// deregistered first, and still served while draining
+	wait(t, fr.deregistered, "deregister")
+
+	resp, err = http.Get("http://" + fr.addr())
+	if err != nil {
+		t.Fatalf("Get() while draining: %v", err)
+	}
+	resp.Body.Close()
+
+	select {
+	case err := <-errC:
+		if err != nil {
+			t.Fatalf("ServeHTTP(): %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("ServeHTTP() not returned")
+	}
+
+	if _, err := net.DialTimeout("tcp", fr.addr(), 100*time.Millisecond); err == nil {
+		t.Fatal("server not stopped")
+	}
+}
+
+func TestServeGRPC(t *testing.T) {
+	fr := newFakeRegistrator(nil)
+	r := newRegistry(t, fr)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errC := make(chan error, 1)
+	go func() {
+		errC <- ServeGRPC(ctx, r, grpc.NewServer(), &registry.Service{ID: "svc-1", Name: "svc"}, WithDrainPeriod(0))
+	}()
+
+	wait(t, fr.registered, "register")
+	cancel()
+	wait(t, fr.deregistered, "deregister")
+
+	select {
+	case err := <-errC:
+		if err != nil {
+			t.Fatalf("ServeGRPC(): %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("ServeGRPC() not returned")
+	}
+}
+
+func TestServeRegisterFailed(t *testing.T) {
+	cases := []struct {
+		name     string
+		reg      *discovery.Registry
+		svc      *registry.Service
+		expected error
+	}{
+		{
+			name:     "register failed",
+			reg:      newRegistry(t, newFakeRegistrator(errors.ErrUnavailable)),
+			svc:      &registry.Service{ID: "svc-1", Name: "svc"},
+			expected: errors.ErrUnavailable,
+		},
+		{
+			name:     "nil registry",
+			svc:      &registry.Service{ID: "svc-1", Name: "svc"},
+			expected: errors.ErrArgument,
+		},
+		{
+			name:     "nil service",
+			reg:      newRegistry(t, newFakeRegistrator(nil)),
+			expected: errors.ErrArgument,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			srv := &http.Server{Addr: "127.0.0.1:0"}
+
+			err := ServeHTTP(context.Background(), tc.reg, srv, tc.svc, WithDrainPeriod(0))
+			if !errors.Is(err, tc.expected) {
+				t.Fatalf("ServeHTTP(): %v, expected %v", err, tc.expected)
+			}
+		})
+	}
+}
@@ -2,6 +2,7 @@ package statics
 
 import (
 	"sync"
+	"time"
 
 	"github.com/leon-gopher/discovery/errors"
 	"github.com/leon-gopher/discovery/registry"
@@ -10,6 +11,7 @@ import (
 type storedService struct {
 	services []*registry.Service
 	err      error
+	loaded   time.Time
 }
 
 // Statics implements registry.Discovery interface for dns, service list.
@@ -72,6 +74,7 @@ func (s *Statics) GetServices(name string, opts ...registry.DiscoveryOption) ([]
 		s.store[key] = &storedService{
 			services: services,
 			err:      err,
+			loaded:   time.Now(),
 		}
 
 		return nil, err
@@ -83,6 +86,7 @@ func (s *Statics) GetServices(name string, opts ...registry.DiscoveryOption) ([]
 		s.store[key] = &storedService{
 			services: nil,
 			err:      err,
+			loaded:   time.Now(),
 		}
 
 		return nil, err
@@ -91,11 +95,36 @@ func (s *Statics) GetServices(name string, opts ...registry.DiscoveryOption) ([]
 	s.store[key] = &storedService{
 		services: services,
 		err:      nil,
+		loaded:   time.Now(),
 	}
 
 	return services, nil
 }
 
+func (s *Statics) Name() string {
+	return "statics"
+}
+
 func (s *Statics) Watch(w registry.Watcher) {}
 
 func (s *Statics) Notify(event registry.Event) {}
+
+// Status reports services loaded.
+func (s *Statics) Status() []*registry.ServiceStatus {
+	s.mux.RLock()
+	defer s.mux.RUnlock()
+
+	statuses := make([]*registry.ServiceStatus, 0, len(s.store))
+	for key, stored := range s.store {
+		statuses = append(statuses, &registry.ServiceStatus{
+			Key:       key,
+			Adapter:   "statics",
+			Source:    registry.SourceStatics,
+			Instances: len(stored.services),
+			Services:  stored.services,
+			UpdatedAt: stored.loaded,
+		})
+	}
+
+	return statuses
+}
@@ -0,0 +1,66 @@
+package discovery
+
+import (
+	"sort"
+
+	"github.com/leon-gopher/discovery/registry"
+)
+
+// Status reports services known by discoveries implemented registry.StatusReporter. Each key is reported by the
+// discovery serving it currently, or the first discovery knowing it if never served, sorted by key.
+func (r *Registry) Status() []*registry.ServiceStatus {
+	reports := make(map[registry.ServiceKey]map[int]*registry.ServiceStatus)
+	var keys []registry.ServiceKey
+
+	for i, disc := range r.opts.discoveries {
+		reporter, ok := disc.(registry.StatusReporter)
+		if !ok {
+			continue
+		}
+
+		for _, status := range reporter.Status() {
+			if _, ok := reports[status.Key]; !ok {
+				reports[status.Key] = make(map[int]*registry.ServiceStatus)
+				keys = append(keys, status.Key)
+			}
+
+			reports[status.Key][i] = status
+		}
+	}
+
+	statuses := make([]*registry.ServiceStatus, 0, len(keys))
+	for _, key := range keys {
+		var status *registry.ServiceStatus
+		if index, ok := r.served.Load(key); ok {
+			status = reports[key][index.(int)]
+		}
+
+		// the first discovery in order reporting it
+		for i := range r.opts.discoveries {
+			report, ok := reports[key][i]
+			if !ok {
+				continue
+			}
+
+			if status == nil {
+				status = report
+			}
+
+			// dump is reported by either consul or file
+			if status.DumpModifiedAt.IsZero() {
+				status.DumpModifiedAt = report.DumpModifiedAt
+			}
+			if len(status.DumpFile) == 0 {
+				status.DumpFile = report.DumpFile
+			}
+		}
+
+		statuses = append(statuses, status)
+	}
+
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Key.ToString() < statuses[j].Key.ToString()
+	})
+
+	return statuses
+}
@@ -0,0 +1,123 @@
+package discovery
+
+import (
+	"context"
+	"sync"
+
+	"github.com/leon-gopher/discovery/registry"
+)
+
+// ServiceEvent represents an update of services, Added, Removed and Updated are computed against the previous event
+// by Service.ID.
+type ServiceEvent struct {
+	Key      registry.ServiceKey
+	Services []*registry.Service
+	Added    []*registry.Service
+	Removed  []*registry.Service
+	Updated  []*registry.Service
+}
+
+// Subscribe returns a channel of service events for the name, the first event contains the current services as added.
+// Updates between two receives are coalesced into one event. The channel is closed when ctx done or the Registry closed.
+func (r *Registry) Subscribe(ctx context.Context, name string, opts ...registry.DiscoveryOption) (<-chan ServiceEvent, error) {
+	o := registry.NewCommonDiscoveryOption(opts...)
+
+	sub := &subscription{
+		key:     registry.NewServiceKey(name, o.Tags, o.DC),
+		out:     make(chan ServiceEvent),
+		notifyC: make(chan struct{}, 1),
+	}
+
+	// watch before lookup, so no update missed in between
+	id := r.addWatcher(registry.WatchFunc(sub.watch))
+
+	services, err := r.LookupServicesContext(ctx, name, opts...)
+	if err != nil {
+		r.removeWatcher(id)
+		return nil, err
+	}
+
+	// updates arrived during lookup are newer
+	sub.mux.Lock()
+	if !sub.updated {
+		sub.latest = services
+	}
+	sub.mux.Unlock()
+
+	r.subs.Add(1)
+	go func() {
+		defer r.subs.Done()
+		defer r.removeWatcher(id)
+
+		sub.loop(ctx, r.closeC)
+	}()
+
+	return sub.out, nil
+}
+
+type subscription struct {
+	key     registry.ServiceKey
+	out     chan ServiceEvent
+	notifyC chan struct{}
+
+	mux     sync.Mutex
+	latest  []*registry.Service
+	updated bool
+}
+
+func (sub *subscription) watch(key registry.ServiceKey, services []*registry.Service) {
+	if key != sub.key {
+		return
+	}
+
+	sub.mux.Lock()
+	sub.latest = services
+	sub.updated = true
+	sub.mux.Unlock()
+
+	select {
+	case sub.notifyC <- struct{}{}:
+	default:
+	}
+}
+
+func (sub *subscription) loop(ctx context.Context, closeC <-chan struct{}) {
+	defer close(sub.out)
+
+	var prev []*registry.Service
+	for synced := false; ; {
+		sub.mux.Lock()
+		services := sub.latest
+		sub.mux.Unlock()
+
+		added, removed, updated := registry.DiffServices(prev, services)
+		if !synced || len(added)+len(removed)+len(updated) > 0 {
+			event := ServiceEvent{
+				Key:      sub.key,
+				Services: services,
+				Added:    added,
+				Removed:  removed,
+				Updated:  updated,
+			}
+
+			select {
+			case sub.out <- event:
+			case <-ctx.Done():
+				return
+			case <-closeC:
+				return
+			}
+
+			prev = services
+			synced = true
+		}
+
+		select {
+		case <-sub.notifyC:
+		case <-ctx.Done():
+			return
+		case <-closeC:
+			return
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package discovery
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/leon-gopher/discovery/errors"
+	"github.com/leon-gopher/discovery/logger"
+	"github.com/leon-gopher/discovery/registry"
+)
+
+// idsOf returns sorted ids of services joined.
+func idsOf(services []*registry.Service) string {
+	ids := make([]string, 0, len(services))
+	for _, service := range services {
+		ids = append(ids, service.ID)
+	}
+	sort.Strings(ids)
+
+	return strings.Join(ids, ",")
+}
+
+func receiveEvent(t *testing.T, events <-chan ServiceEvent) ServiceEvent {
+	t.Helper()
+
+	select {
+	case event, ok := <-events:
+		if !ok {
+			t.Fatal("events closed")
+		}
+		return event
+	case <-time.After(time.Second):
+		t.Fatal("no event received")
+	}
+
+	return ServiceEvent{}
+}
+
+func TestSubscribe(t *testing.T) {
+	key := registry.NewServiceKey("svc", nil, "")
+	disc := &fakeDiscovery{name: "fake", services: []*registry.Service{{ID: "a"}, {ID: "b"}}}
+
+	r, err := NewRegistry(WithDiscoveries(disc), WithLogger(logger.Nop()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	events, err := r.Subscribe(context.Background(), "svc")
+	if err != nil {
+		t.Fatalf("Subscribe(): %v", err)
+	}
+
+	// current services as added
+	event := receiveEvent(t, events)
+	if event.Key != key || idsOf(event.Added) != "a,b" || len(event.Removed)+len(event.Updated) != 0 {
+		t.Fatalf("first event: %+v", event)
+	}
+
+	disc.push(key, []*registry.Service{{ID: "b", Weight: 10}, {ID: "c"}})
+
+	event = receiveEvent(t, events)
+	if idsOf(event.Added) != "c" || idsOf(event.Removed) != "a" || idsOf(event.Updated) != "b" || idsOf(event.Services) != "b,c" {
+		t.Fatalf("event: %+v", event)
+	}
+
+	// updates of other keys are not subscribed
+	disc.push(registry.NewServiceKey("other", nil, ""), []*registry.Service{{ID: "x"}})
+
+	select {
+	case event := <-events:
+		t.Fatalf("unexpected event: %+v", event)
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	if err := r.Close(context.Background()); err != nil {
+		t.Fatalf("Close(): %v", err)
+	}
+	if _, ok := <-events; ok {
+		t.Fatal("events not closed by Close()")
+	}
+}
+
+func TestSubscribeCanceled(t *testing.T) {
+	disc := &fakeDiscovery{name: "fake", services: []*registry.Service{{ID: "a"}}}
+
+	r, err := NewRegistry(WithDiscoveries(disc), WithLogger(logger.Nop()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close(context.Background())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	events, err := r.Subscribe(ctx, "svc")
+	if err != nil {
+		t.Fatalf("Subscribe(): %v", err)
+	}
+
+	receiveEvent(t, events)
+	cancel()
+
+	select {
+	case _, ok := <-events:
+		if ok {
+			t.Fatal("event received after canceled")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("events not closed by canceling")
+	}
+
+	// lookup failed
+	disc.set(nil, errors.Wrap(errors.ErrCanceled))
+	if _, err := r.Subscribe(context.Background(), "svc"); !errors.Is(err, errors.ErrCanceled) {
+		t.Fatalf("Subscribe(): %v, expected %v", err, errors.ErrCanceled)
+	}
+}
@@ -0,0 +1,41 @@
+package discovery
+
+import (
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/leon-gopher/discovery/registry"
+)
+
+// TracerName is instrumentation name of spans created by the Registry.
+const TracerName = "github.com/leon-gopher/discovery"
+
+// attributes of spans
+const (
+	AttrServiceKey  = attribute.Key("discovery.service.key")
+	AttrServiceName = attribute.Key("discovery.service.name")
+	AttrAdapter     = attribute.Key("discovery.adapter")
+	AttrInstances   = attribute.Key("discovery.instances")
+	AttrFallback    = attribute.Key("discovery.fallback")
+)
+
+func (r *Registry) tracer() trace.Tracer {
+	return r.opts.tracerProvider.Tracer(TracerName)
+}
+
+func keyAttributes(key registry.ServiceKey) []attribute.KeyValue {
+	return []attribute.KeyValue{
+		AttrServiceKey.String(key.ToString()),
+		AttrServiceName.String(key.Name),
+	}
+}
+
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	span.End()
+}
@@ -0,0 +1,103 @@
+package discovery
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/leon-gopher/discovery/errors"
+	"github.com/leon-gopher/discovery/registry"
+	"golang.org/x/sync/errgroup"
+)
+
+// ServiceDependency declares a service required before serving traffic, see WaitForDependencies.
+type ServiceDependency struct {
+	Name         string
+	MinInstances int
+	Options      []registry.DiscoveryOption
+}
+
+// WaitForServices blocks until at least minInstances services of the name discoverable, or ctx done. It is driven by
+// watch updates of discoveries, and looks up again every DefaultWaitRetryInterval until the first discovery serves the
+// name, e.g. registry unreachable and served by fallback, because discoveries only watch after a successful lookup.
+func (r *Registry) WaitForServices(ctx context.Context, name string, minInstances int, opts ...registry.DiscoveryOption) ([]*registry.Service, error) {
+	if minInstances <= 0 {
+		minInstances = 1
+	}
+
+	o := registry.NewCommonDiscoveryOption(opts...)
+
+	sub := &subscription{
+		key:     registry.NewServiceKey(name, o.Tags, o.DC),
+		notifyC: make(chan struct{}, 1),
+	}
+
+	// watch before lookup, so no update missed in between
+	id := r.addWatcher(registry.WatchFunc(sub.watch))
+	defer r.removeWatcher(id)
+
+	services, err := r.LookupServicesContext(ctx, name, opts...)
+	if len(services) >= minInstances {
+		return services, nil
+	}
+	if err != nil {
+		kind := errors.KindOf(err)
+		if kind != errors.KindUnknown && !kind.Temporary() {
+			return nil, err
+		}
+	}
+
+	ticker := time.NewTicker(DefaultWaitRetryInterval)
+	defer ticker.Stop()
+
+	retryC := ticker.C
+	if r.servedByFirst(sub.key) {
+		retryC = nil
+	}
+
+	r.opts.logger.Info("Registry.WaitForServices() waiting", "service", sub.key.ToString(), "min", minInstances, "current", len(services))
+
+	for {
+		select {
+		case <-sub.notifyC:
+			sub.mux.Lock()
+			services = sub.latest
+			sub.mux.Unlock()
+
+		case <-retryC:
+			if retried, rerr := r.LookupServicesContext(ctx, name, opts...); rerr == nil {
+				services = retried
+			}
+
+		case <-ctx.Done():
+			err = fmt.Errorf("%d of %d instances: %w", len(services), minInstances, ctx.Err())
+
+			return services, errors.Wrap(registry.NewError("WaitForServices", "registry", sub.key, err))
+		}
+
+		if len(services) >= minInstances {
+			return services, nil
+		}
+
+		// watch of the first discovery established, updates come from it
+		if r.servedByFirst(sub.key) {
+			retryC = nil
+		}
+	}
+}
+
+// WaitForDependencies waits for all dependencies concurrently, it returns the first error and stops waiting others.
+func (r *Registry) WaitForDependencies(ctx context.Context, deps ...ServiceDependency) error {
+	group, ctx := errgroup.WithContext(ctx)
+
+	for _, dep := range deps {
+		dep := dep
+
+		group.Go(func() error {
+			_, err := r.WaitForServices(ctx, dep.Name, dep.MinInstances, dep.Options...)
+			return err
+		})
+	}
+
+	return group.Wait()
+}
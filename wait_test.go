@@ -0,0 +1,141 @@
+package discovery
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/leon-gopher/discovery/errors"
+	"github.com/leon-gopher/discovery/logger"
+	"github.com/leon-gopher/discovery/registry"
+)
+
+func TestWaitForServices(t *testing.T) {
+	key := registry.NewServiceKey("svc", nil, "")
+
+	cases := []struct {
+		name string
+		// err of the first lookup
+		err error
+		// changes discoveries while waiting
+		change  func(first, second *fakeDiscovery)
+		timeout time.Duration
+		ids     string
+		// error expected
+		expected error
+	}{
+		{
+			name: "enough",
+			ids:  "a,b",
+		},
+		{
+			name: "watched",
+			change: func(first, second *fakeDiscovery) {
+				first.push(key, []*registry.Service{{ID: "a"}, {ID: "b"}, {ID: "c"}})
+			},
+			ids: "a,b,c",
+		},
+		{
+			name: "retried while served by fallback",
+			err:  errors.ErrUnavailable,
+			change: func(first, second *fakeDiscovery) {
+				first.set([]*registry.Service{{ID: "a"}, {ID: "b"}, {ID: "c"}}, nil)
+			},
+			timeout: 3 * DefaultWaitRetryInterval,
+			ids:     "a,b,c",
+		},
+		{
+			name:     "timed out",
+			err:      errors.ErrUnavailable,
+			timeout:  50 * time.Millisecond,
+			expected: context.DeadlineExceeded,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			first := &fakeDiscovery{name: "first", services: []*registry.Service{{ID: "a"}, {ID: "b"}}}
+			second := &fakeDiscovery{name: "second", services: []*registry.Service{{ID: "a"}}}
+			if tc.err != nil {
+				first.set(nil, errors.Wrap(tc.err))
+			}
+
+			r, err := NewRegistry(WithDiscoveries(first, second), WithLogger(logger.Nop()))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer r.Close(context.Background())
+
+			minInstances := 2
+			if tc.change != nil {
+				minInstances = 3
+
+				time.AfterFunc(20*time.Millisecond, func() {
+					tc.change(first, second)
+				})
+			}
+
+			ctx := context.Background()
+			if tc.timeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, tc.timeout)
+				defer cancel()
+			}
+
+			services, err := r.WaitForServices(ctx, "svc", minInstances)
+			if tc.expected != nil {
+				if !errors.Is(err, tc.expected) {
+					t.Fatalf("WaitForServices(): %v, expected %v", err, tc.expected)
+				}
+				return
+			}
+
+			if err != nil || idsOf(services) != tc.ids {
+				t.Fatalf("WaitForServices(): %s, %v, expected %s", idsOf(services), err, tc.ids)
+			}
+		})
+	}
+}
+
+func TestWaitForServicesFailed(t *testing.T) {
+	disc := &fakeDiscovery{name: "fake", err: errors.Wrap(errors.ErrArgument)}
+
+	r, err := NewRegistry(WithDiscoveries(disc), WithLogger(logger.Nop()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close(context.Background())
+
+	// permanent errors never retried
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	if _, err := r.WaitForServices(ctx, "svc", 1); !errors.Is(err, errors.ErrArgument) {
+		t.Fatalf("WaitForServices(): %v, expected %v", err, errors.ErrArgument)
+	}
+}
+
+func TestWaitForDependencies(t *testing.T) {
+	disc := &fakeDiscovery{name: "fake", services: []*registry.Service{{ID: "a"}}}
+
+	r, err := NewRegistry(WithDiscoveries(disc), WithLogger(logger.Nop()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close(context.Background())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	err = r.WaitForDependencies(ctx,
+		ServiceDependency{Name: "svc", MinInstances: 1},
+		ServiceDependency{Name: "svc", MinInstances: 2},
+	)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("WaitForDependencies(): %v, expected %v", err, context.DeadlineExceeded)
+	}
+
+	if err := r.WaitForDependencies(context.Background(), ServiceDependency{Name: "svc", MinInstances: 1}); err != nil {
+		t.Fatalf("WaitForDependencies(): %v", err)
+	}
+}
@@ -0,0 +1,134 @@
+package discovery
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+
+	"github.com/leon-gopher/discovery/errors"
+	"github.com/leon-gopher/discovery/logger"
+	"github.com/leon-gopher/discovery/registry"
+)
+
+// watcherQueue dispatches updates to a watcher in its own goroutine. It holds at most size pending service keys,
+// and a pending key is coalesced with the latest services.
+type watcherQueue struct {
+	id      uint64
+	watcher registry.Watcher
+	size    int
+	log     logger.Structured
+
+	mux     sync.Mutex
+	keys    []registry.ServiceKey
+	pending map[registry.ServiceKey][]*registry.Service
+
+	notifyC chan struct{}
+	stopC   chan struct{}
+	stopped sync.Once
+	doneC   chan struct{}
+
+	coalesced uint64
+	dropped   uint64
+}
+
+func newWatcherQueue(id uint64, w registry.Watcher, size int, log logger.Structured) *watcherQueue {
+	return &watcherQueue{
+		id:      id,
+		watcher: w,
+		size:    size,
+		log:     log.With("watcher", id),
+		pending: make(map[registry.ServiceKey][]*registry.Service),
+		notifyC: make(chan struct{}, 1),
+		stopC:   make(chan struct{}),
+		doneC:   make(chan struct{}),
+	}
+}
+
+func (q *watcherQueue) enqueue(key registry.ServiceKey, services []*registry.Service) {
+	q.mux.Lock()
+	if _, ok := q.pending[key]; ok {
+		q.pending[key] = services
+		q.mux.Unlock()
+
+		coalesced := atomic.AddUint64(&q.coalesced, 1)
+		q.log.Debug("watcher.enqueue() coalesced", "service", key.ToString(), "total", coalesced)
+		return
+	}
+
+	if len(q.keys) >= q.size {
+		q.mux.Unlock()
+
+		dropped := atomic.AddUint64(&q.dropped, 1)
+		q.log.Warn("watcher.enqueue() dropped with full queue", "service", key.ToString(), "size", q.size, "total", dropped)
+		return
+	}
+
+	q.keys = append(q.keys, key)
+	q.pending[key] = services
+	q.mux.Unlock()
+
+	select {
+	case q.notifyC <- struct{}{}:
+	default:
+	}
+}
+
+func (q *watcherQueue) dequeue() (key registry.ServiceKey, services []*registry.Service, ok bool) {
+	q.mux.Lock()
+	defer q.mux.Unlock()
+
+	if len(q.keys) == 0 {
+		return
+	}
+
+	key = q.keys[0]
+	services = q.pending[key]
+	ok = true
+
+	q.keys = q.keys[1:]
+	delete(q.pending, key)
+	return
+}
+
+func (q *watcherQueue) loop() {
+	defer close(q.doneC)
+
+	for {
+		select {
+		case <-q.notifyC:
+		case <-q.stopC:
+			return
+		}
+
+		for {
+			key, services, ok := q.dequeue()
+			if !ok {
+				break
+			}
+
+			q.watcher.Watch(key, services)
+
+			select {
+			case <-q.stopC:
+				return
+			default:
+			}
+		}
+	}
+}
+
+func (q *watcherQueue) stop() {
+	q.stopped.Do(func() {
+		close(q.stopC)
+	})
+}
+
+// wait blocks until the dispatching goroutine exited or ctx done.
+func (q *watcherQueue) wait(ctx context.Context) error {
+	select {
+	case <-q.doneC:
+		return nil
+	case <-ctx.Done():
+		return errors.Wrap(ctx.Err())
+	}
+}
@@ -0,0 +1,129 @@
+package discovery
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/leon-gopher/discovery/logger"
+	"github.com/leon-gopher/discovery/registry"
+)
+
+type enqueued struct {
+	name      string
+	instances int
+}
+
+func newInstances(n int) []*registry.Service {
+	services := make([]*registry.Service, 0, n)
+	for i := 0; i < n; i++ {
+		services = append(services, &registry.Service{ID: strconv.Itoa(i)})
+	}
+
+	return services
+}
+
+func TestWatcherQueueEnqueue(t *testing.T) {
+	cases := []struct {
+		name      string
+		size      int
+		enqueues  []enqueued
+		expected  []enqueued
+		coalesced uint64
+		dropped   uint64
+	}{
+		{
+			name:     "fifo",
+			size:     4,
+			enqueues: []enqueued{{"a", 1}, {"b", 2}, {"c", 3}},
+			expected: []enqueued{{"a", 1}, {"b", 2}, {"c", 3}},
+		},
+		{
+			name:      "coalesce with latest in place",
+			size:      4,
+			enqueues:  []enqueued{{"a", 1}, {"b", 2}, {"a", 3}, {"a", 4}},
+			expected:  []enqueued{{"a", 4}, {"b", 2}},
+			coalesced: 2,
+		},
+		{
+			name:     "drop new keys when full",
+			size:     2,
+			enqueues: []enqueued{{"a", 1}, {"b", 2}, {"c", 3}, {"d", 4}},
+			expected: []enqueued{{"a", 1}, {"b", 2}},
+			dropped:  2,
+		},
+		{
+			name:      "coalesce when full",
+			size:      2,
+			enqueues:  []enqueued{{"a", 1}, {"b", 2}, {"c", 3}, {"b", 5}},
+			expected:  []enqueued{{"a", 1}, {"b", 5}},
+			coalesced: 1,
+			dropped:   1,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			q := newWatcherQueue(1, registry.WatchFunc(func(registry.ServiceKey, []*registry.Service) {}), tc.size, logger.Nop())
+
+			for _, e := range tc.enqueues {
+				q.enqueue(registry.NewServiceKey(e.name, nil, ""), newInstances(e.instances))
+			}
+
+			var actual []enqueued
+			for {
+				key, services, ok := q.dequeue()
+				if !ok {
+					break
+				}
+
+				actual = append(actual, enqueued{key.Name, len(services)})
+			}
+
+			if len(actual) != len(tc.expected) {
+				t.Fatalf("dequeued %v, expected %v", actual, tc.expected)
+			}
+			for i := range actual {
+				if actual[i] != tc.expected[i] {
+					t.Fatalf("dequeued %v, expected %v", actual, tc.expected)
+				}
+			}
+
+			if q.coalesced != tc.coalesced || q.dropped != tc.dropped {
+				t.Fatalf("coalesced %d, dropped %d, expected %d, %d", q.coalesced, q.dropped, tc.coalesced, tc.dropped)
+			}
+		})
+	}
+}
+
+func TestWatcherQueueLoop(t *testing.T) {
+	watched := make(chan registry.ServiceKey, 10)
+
+	q := newWatcherQueue(1, registry.WatchFunc(func(key registry.ServiceKey, _ []*registry.Service) {
+		watched <- key
+	}), 10, logger.Nop())
+	go q.loop()
+
+	key := registry.NewServiceKey("a", nil, "")
+	q.enqueue(key, newInstances(1))
+
+	select {
+	case actual := <-watched:
+		if actual != key {
+			t.Fatalf("watched %v, expected %v", actual, key)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("update not dispatched")
+	}
+
+	q.stop()
+	q.stop()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	if err := q.wait(ctx); err != nil {
+		t.Fatalf("wait(): %v", err)
+	}
+}